@@ -0,0 +1,49 @@
+package saj
+
+type ObjectBuilder struct {
+	obj Object
+}
+
+func NewObjectBuilder() *ObjectBuilder {
+	return &ObjectBuilder{
+		obj: make(Object),
+	}
+}
+
+func (b *ObjectBuilder) Set(key string, el Element) *ObjectBuilder {
+	b.obj[key] = el
+	return b
+}
+
+// Build returns a copy of the object built so far, so that the builder can
+// keep being used without changing it.
+func (b *ObjectBuilder) Build() Object {
+	obj := make(Object, len(b.obj))
+	for k, v := range b.obj {
+		obj[k] = v
+	}
+	return obj
+}
+
+type ArrayBuilder struct {
+	arr Array
+}
+
+func NewArrayBuilder() *ArrayBuilder {
+	return &ArrayBuilder{
+		arr: make(Array, 0),
+	}
+}
+
+func (b *ArrayBuilder) Append(els ...Element) *ArrayBuilder {
+	b.arr = append(b.arr, els...)
+	return b
+}
+
+// Build returns a copy of the array built so far, so that the builder can
+// keep being used without changing it.
+func (b *ArrayBuilder) Build() Array {
+	arr := make(Array, len(b.arr))
+	copy(arr, b.arr)
+	return arr
+}
@@ -0,0 +1,45 @@
+package saj
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestBuilder(t *testing.T) {
+	const input = `{"name": "foo", "age": 10, "tags": ["a", "b", null], "empty": []}`
+
+	age, _ := Number("10")
+	got := NewObjectBuilder().
+		Set("name", String("foo")).
+		Set("age", age).
+		Set("tags", NewArrayBuilder().Append(String("a"), String("b")).Append(Null()).Build()).
+		Set("empty", NewArrayBuilder().Build()).
+		Build()
+
+	want, err := New(strings.NewReader(input)).Read()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if !reflect.DeepEqual(ToGo(got), ToGo(want)) {
+		t.Errorf("built object mismatched: want %v, got %v", want, got)
+	}
+}
+
+func TestBuilder_Reuse(t *testing.T) {
+	ob := NewObjectBuilder().Set("a", IntFrom(1))
+	obj := ob.Build()
+	ob.Set("a", IntFrom(2)).Set("b", IntFrom(3))
+	if !Equal(obj, Object{"a": IntFrom(1)}) {
+		t.Errorf("built object changed by the builder: %v", obj)
+	}
+
+	ab := NewArrayBuilder().Append(IntFrom(1), IntFrom(2))
+	arr := ab.Build()
+	first := append(arr[:1], IntFrom(3))
+	ab.Append(IntFrom(4))
+	second := ab.Build()
+	if !Equal(first, Array{IntFrom(1), IntFrom(3)}) || !Equal(second, Array{IntFrom(1), IntFrom(2), IntFrom(4)}) {
+		t.Errorf("built arrays share memory with the builder: %v, %v", first, second)
+	}
+}
@@ -0,0 +1,60 @@
+package saj
+
+import (
+	"bufio"
+	"compress/gzip"
+	"compress/zlib"
+	"fmt"
+	"io"
+)
+
+type Codec int
+
+const (
+	CodecAuto Codec = iota
+	CodecNone
+	CodecGzip
+	CodecZlib
+)
+
+// NewCompressed returns a reader decompressing r with codec. CodecAuto
+// recognizes gzip and zlib streams from their header and reads anything
+// else as is.
+func NewCompressed(r io.Reader, codec Codec, opts ...Option) (*Reader, error) {
+	var (
+		rs  = bufio.NewReader(r)
+		err error
+	)
+	if codec == CodecAuto {
+		codec = detectCodec(rs)
+	}
+	switch codec {
+	case CodecNone:
+		r = rs
+	case CodecGzip:
+		r, err = gzip.NewReader(rs)
+	case CodecZlib:
+		r, err = zlib.NewReader(rs)
+	default:
+		return nil, fmt.Errorf("compress: unsupported codec %d", codec)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("compress: %w", err)
+	}
+	return NewWithOptions(r, opts...), nil
+}
+
+func detectCodec(rs *bufio.Reader) Codec {
+	b, _ := rs.Peek(2)
+	if len(b) < 2 {
+		return CodecNone
+	}
+	switch {
+	case b[0] == 0x1f && b[1] == 0x8b:
+		return CodecGzip
+	case b[0]&0x0f == 8 && b[1]&0x20 == 0 && (uint16(b[0])<<8|uint16(b[1]))%31 == 0:
+		return CodecZlib
+	default:
+		return CodecNone
+	}
+}
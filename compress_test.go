@@ -0,0 +1,68 @@
+package saj
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestNewCompressed(t *testing.T) {
+	const input = `{"name": "foo", "tags": ["a", "b"]} [1]`
+
+	compress := func(w io.WriteCloser, buf *bytes.Buffer) []byte {
+		io.WriteString(w, input)
+		w.Close()
+		return buf.Bytes()
+	}
+	var gz, zl bytes.Buffer
+	data := []struct {
+		Name  string
+		Input []byte
+		Codec Codec
+	}{
+		{Name: "plain", Input: []byte(input), Codec: CodecAuto},
+		{Name: "plain", Input: []byte(input), Codec: CodecNone},
+		{Name: "gzip", Input: compress(gzip.NewWriter(&gz), &gz), Codec: CodecAuto},
+		{Name: "gzip", Input: gz.Bytes(), Codec: CodecGzip},
+		{Name: "zlib", Input: compress(zlib.NewWriter(&zl), &zl), Codec: CodecAuto},
+		{Name: "zlib", Input: zl.Bytes(), Codec: CodecZlib},
+	}
+	want, _ := New(strings.NewReader(input)).Read()
+	for _, d := range data {
+		r, err := NewCompressed(bytes.NewReader(d.Input), d.Codec)
+		if err != nil {
+			t.Errorf("%s(%d): unexpected error: %s", d.Name, d.Codec, err)
+			continue
+		}
+		got, err := r.Read()
+		if err != nil {
+			t.Errorf("%s(%d): unexpected error: %s", d.Name, d.Codec, err)
+			continue
+		}
+		if !Equal(got, want) {
+			t.Errorf("%s(%d): want %v, got %v", d.Name, d.Codec, Value(want), Value(got))
+		}
+		if _, err := r.Read(); err != nil {
+			t.Errorf("%s(%d): second value not read: %s", d.Name, d.Codec, err)
+		}
+	}
+
+	if _, err := NewCompressed(strings.NewReader(input), CodecGzip); err == nil {
+		t.Errorf("plain input accepted as gzip")
+	}
+	if _, err := NewCompressed(strings.NewReader(input), Codec(42)); err == nil {
+		t.Errorf("unknown codec accepted")
+	}
+	for _, str := range []string{"1", "80", "8O"} {
+		r, err := NewCompressed(strings.NewReader(str), CodecAuto)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %s", str, err)
+		}
+		if _, err := r.Read(); err != nil && str != "8O" {
+			t.Errorf("%s: unexpected error: %s", str, err)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package saj
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+)
+
+// ToGo returns the same values as json.Unmarshal into an any, all numbers
+// being float64. Value differs only by keeping integers as int64.
+func ToGo(el Element) any {
+	return toGo(el, true)
+}
+
+func Value(el Element) any {
+	return toGo(el, false)
+}
+
+func toGo(el Element, floats bool) any {
+	switch el := plain(el).(type) {
+	case Object:
+		obj := make(map[string]any, len(el))
+		for k, v := range el {
+			obj[k] = toGo(v, floats)
+		}
+		return obj
+	case MultiObject:
+		return toGo(el.lists(), floats)
+	case Array:
+		arr := make([]any, 0, len(el))
+		for _, v := range el {
+			arr = append(arr, toGo(v, floats))
+		}
+		return arr
+	case Literal[int64]:
+		if floats {
+			return float64(el.Literal)
+		}
+		return el.Literal
+	case Literal[struct{}]:
+		return nil
+	case interface{ Value() any }:
+		return el.Value()
+	default:
+		return nil
+	}
+}
+
+func FromGo(v any) (Element, error) {
+	if el, ok := v.(Element); ok {
+		return el, nil
+	}
+	return fromValue(reflect.ValueOf(v))
+}
+
+func fromValue(v reflect.Value) (Element, error) {
+	if !v.IsValid() {
+		return Null(), nil
+	}
+	if el, ok := v.Interface().(Element); ok {
+		return el, nil
+	}
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			return Null(), nil
+		}
+		return fromValue(v.Elem())
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			return nil, fmt.Errorf("%s: unsupported map key type", v.Type())
+		}
+		if v.IsNil() {
+			return Null(), nil
+		}
+		obj := make(Object, v.Len())
+		for it := v.MapRange(); it.Next(); {
+			el, err := fromValue(it.Value())
+			if err != nil {
+				return nil, err
+			}
+			obj[it.Key().String()] = el
+		}
+		return obj, nil
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			return Null(), nil
+		}
+		arr := make(Array, 0, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			el, err := fromValue(v.Index(i))
+			if err != nil {
+				return nil, err
+			}
+			arr = append(arr, el)
+		}
+		return arr, nil
+	case reflect.String:
+		return String(v.String()), nil
+	case reflect.Bool:
+		return Literal[bool]{Literal: v.Bool()}, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return IntFrom(v.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if n := v.Uint(); n <= math.MaxInt64 {
+			return IntFrom(int64(n)), nil
+		}
+		return Literal[float64]{Literal: float64(v.Uint())}, nil
+	case reflect.Float32, reflect.Float64:
+		return Literal[float64]{Literal: v.Float()}, nil
+	default:
+		return nil, fmt.Errorf("%s: unsupported type", v.Type())
+	}
+}
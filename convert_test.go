@@ -0,0 +1,187 @@
+package saj
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestValue(t *testing.T) {
+	data := []string{
+		`"foobar"`,
+		`-3.14`,
+		`true`,
+		`null`,
+		`[]`,
+		`{}`,
+		`[1, "two", [null, false]]`,
+		`{"name": "foo", "tags": ["a", "b"], "parent": {"id": 1e3, "root": null}}`,
+	}
+	for _, d := range data {
+		e, err := New(strings.NewReader(d)).Read()
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", d, err)
+			continue
+		}
+		var want any
+		if err := json.Unmarshal([]byte(d), &want); err != nil {
+			t.Fatalf("%s: unexpected error: %s", d, err)
+		}
+		if got := Value(e); !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: want %#v, got %#v", d, want, got)
+		}
+	}
+}
+
+func TestLiteral_Value(t *testing.T) {
+	if v := String("foo").Value(); v != "foo" {
+		t.Errorf("unexpected string value: %#v", v)
+	}
+	n, _ := Number("42")
+	if v := n.Value(); v != float64(42) {
+		t.Errorf("unexpected number value: %#v", v)
+	}
+	b, _ := Bool("true")
+	if v := b.Value(); v != true {
+		t.Errorf("unexpected bool value: %#v", v)
+	}
+}
+
+func TestToGo(t *testing.T) {
+	files, err := filepath.Glob("data/*.json")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	for _, f := range files {
+		buf, err := os.ReadFile(f)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %s", f, err)
+		}
+		e, err := New(bytes.NewReader(buf)).Read()
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", f, err)
+			continue
+		}
+		var want any
+		if err := json.Unmarshal(buf, &want); err != nil {
+			t.Fatalf("%s: unexpected error: %s", f, err)
+		}
+		if got := ToGo(e); !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: document mismatched", f)
+		}
+		e, err = NewWithOptions(bytes.NewReader(buf), WithIntegers()).Read()
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %s", f, err)
+		}
+		if got := ToGo(e); !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: document mismatched with integers", f)
+		}
+	}
+	if v := ToGo(IntFrom(42)); v != float64(42) {
+		t.Errorf("integer should convert to float64, got %#v", v)
+	}
+	if v := Value(IntFrom(42)); v != int64(42) {
+		t.Errorf("integer should keep its type with Value, got %#v", v)
+	}
+}
+
+func TestFromGo(t *testing.T) {
+	data := []any{
+		nil,
+		"foobar",
+		3.14,
+		42,
+		uint8(7),
+		true,
+		[]any{},
+		map[string]any{},
+		[]any{1, "two", []any{nil, false}},
+		map[string]any{
+			"name":   "foo",
+			"tags":   []string{"a", "b"},
+			"parent": map[string]any{"id": 1e3, "root": nil},
+		},
+	}
+	for _, d := range data {
+		e, err := FromGo(d)
+		if err != nil {
+			t.Errorf("%v: unexpected error: %s", d, err)
+			continue
+		}
+		var str strings.Builder
+		if err := NewEncoder(&str).Encode(e); err != nil {
+			t.Errorf("%v: unexpected error: %s", d, err)
+			continue
+		}
+		want, _ := json.Marshal(d)
+		var w, g any
+		json.Unmarshal(want, &w)
+		json.Unmarshal([]byte(str.String()), &g)
+		if !reflect.DeepEqual(g, w) {
+			t.Errorf("%v: want %s, got %s", d, want, str.String())
+		}
+	}
+}
+
+func TestFromGo_Integer(t *testing.T) {
+	data := []struct {
+		Value any
+		Want  string
+	}{
+		{Value: int64(1<<53 + 1), Want: `9007199254740993`},
+		{Value: int64(math.MinInt64), Want: `-9223372036854775808`},
+		{Value: uint64(math.MaxInt64), Want: `9223372036854775807`},
+		{Value: int8(-7), Want: `-7`},
+	}
+	for _, d := range data {
+		e, err := FromGo(d.Value)
+		if err != nil {
+			t.Errorf("%v: unexpected error: %s", d.Value, err)
+			continue
+		}
+		if e.Type() != TypeInteger {
+			t.Errorf("%v: want integer, got %s", d.Value, e.Type())
+		}
+		var str strings.Builder
+		if err := NewEncoder(&str).Encode(e); err != nil {
+			t.Errorf("%v: unexpected error: %s", d.Value, err)
+			continue
+		}
+		if str.String() != d.Want {
+			t.Errorf("%v: want %s, got %s", d.Value, d.Want, str.String())
+		}
+	}
+}
+
+func TestFromGo_Error(t *testing.T) {
+	data := []any{
+		make(chan int),
+		func() {},
+		map[int]any{1: "one"},
+		[]any{"ok", complex(1, 2)},
+	}
+	for _, d := range data {
+		if e, err := FromGo(d); err == nil {
+			t.Errorf("%T: unsupported type converted to %v", d, e)
+		}
+	}
+}
+
+func TestValue_MultiObject(t *testing.T) {
+	e, err := NewWithOptions(strings.NewReader(`{"a": 1, "b": {"c": true}, "a": "2"}`), WithMultiObjects()).Read()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	want := map[string]any{
+		"a": []any{1.0, "2"},
+		"b": []any{map[string]any{"c": []any{true}}},
+	}
+	if got := ToGo(e); !reflect.DeepEqual(got, want) {
+		t.Errorf("want %#v, got %#v", want, got)
+	}
+}
@@ -0,0 +1,339 @@
+package saj
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+var elementType = reflect.TypeOf((*Element)(nil)).Elem()
+
+type decoder struct {
+	disallow bool
+}
+
+func (r *Reader) decoder() decoder {
+	return decoder{
+		disallow: r.disallow,
+	}
+}
+
+func (r *Reader) Decode(v any) error {
+	el, err := r.Read()
+	if err != nil {
+		return err
+	}
+	return r.decoder().decode(el, v)
+}
+
+// DecodeStream is like Decode but assigns the fields of structs as their
+// members are read instead of building the objects first. Members without
+// a matching field are skipped without being decoded.
+func (r *Reader) DecodeStream(v any) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return fmt.Errorf("decode: non-nil pointer expected, got %T", v)
+	}
+	r.count = 0
+	r.skipBlank()
+	return r.decodeStream(rv.Elem(), nil)
+}
+
+func (r *Reader) decodeStream(v reflect.Value, path []string) error {
+	t, err := r.Peek()
+	if err != nil {
+		return err
+	}
+	typ := v.Type()
+	if typ.Kind() == reflect.Pointer && t == TypeObject {
+		typ = typ.Elem()
+	}
+	if t != TypeObject || typ.Kind() != reflect.Struct || v.Type().Implements(elementType) {
+		el, err := r.read()
+		if err != nil {
+			return err
+		}
+		return r.decoder().decodeValue(el, v, path)
+	}
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			v.Set(reflect.New(typ))
+		}
+		v = v.Elem()
+	}
+
+	r.next()
+	if err := r.incr(); err != nil {
+		return err
+	}
+	if err := r.enter(); err != nil {
+		return err
+	}
+	defer r.leave()
+	for i := 0; ; i++ {
+		more, err := r.elem(rcurly, i)
+		if err != nil {
+			return err
+		}
+		if !more {
+			break
+		}
+		key, err := r.key()
+		if err != nil {
+			return err
+		}
+		if err := r.keyLimit(i + 1); err != nil {
+			return err
+		}
+		f, ok := fieldByKey(v, key)
+		if !ok {
+			if r.disallow {
+				return unknownField(key, path)
+			}
+			if err := r.scan(&visitor{}); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := r.decodeStream(f, append(path, key)); err != nil {
+			return err
+		}
+	}
+	r.skipBlank()
+	return nil
+}
+
+func Decode(el Element, v any) error {
+	return decoder{}.decode(el, v)
+}
+
+func DecodeFile(file string, opts ...Option) (Element, error) {
+	buf, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("decode: %w", err)
+	}
+	r := NewBytes(buf, opts...)
+	el, err := r.Read()
+	if err != nil {
+		return nil, fmt.Errorf("decode: %s: %w", file, err)
+	}
+	if _, err := r.Peek(); !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("decode: %s: unexpected data after value", file)
+	}
+	return el, nil
+}
+
+func DecodeArray[T any](r *Reader) ([]T, error) {
+	el, err := r.Read()
+	if err != nil {
+		return nil, err
+	}
+	arr, ok := AsArray(el)
+	if !ok {
+		return nil, fmt.Errorf("decode: array expected, got %s", el.Type())
+	}
+	list := make([]T, len(arr))
+	for i := range arr {
+		if err := r.decoder().decodeValue(arr[i], reflect.ValueOf(&list[i]).Elem(), []string{strconv.Itoa(i)}); err != nil {
+			return nil, err
+		}
+	}
+	return list, nil
+}
+
+func (d decoder) decode(el Element, v any) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return fmt.Errorf("decode: non-nil pointer expected, got %T", v)
+	}
+	return d.decodeValue(el, rv.Elem(), nil)
+}
+
+func (d decoder) decodeValue(el Element, v reflect.Value, path []string) error {
+	if v.Type() == elementType || (v.Type().Implements(elementType) && reflect.TypeOf(el) == v.Type()) {
+		v.Set(reflect.ValueOf(el))
+		return nil
+	}
+	if v.Kind() == reflect.Pointer {
+		if IsNull(el) {
+			v.Set(reflect.Zero(v.Type()))
+			return nil
+		}
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return d.decodeValue(el, v.Elem(), path)
+	}
+	if IsNull(el) {
+		return nil
+	}
+	switch v.Kind() {
+	case reflect.Interface:
+		if v.NumMethod() > 0 {
+			return decodeError(el, v, path)
+		}
+		if x := Value(el); x != nil {
+			v.Set(reflect.ValueOf(x))
+		}
+	case reflect.Struct:
+		obj, ok := members(el)
+		if !ok {
+			return decodeError(el, v, path)
+		}
+		return d.decodeStruct(obj, v, path)
+	case reflect.Map:
+		obj, ok := members(el)
+		if !ok || v.Type().Key().Kind() != reflect.String {
+			return decodeError(el, v, path)
+		}
+		if v.IsNil() {
+			v.Set(reflect.MakeMapWithSize(v.Type(), len(obj)))
+		}
+		for k, e := range obj {
+			val := reflect.New(v.Type().Elem()).Elem()
+			if err := d.decodeValue(e, val, append(path, k)); err != nil {
+				return err
+			}
+			v.SetMapIndex(reflect.ValueOf(k).Convert(v.Type().Key()), val)
+		}
+	case reflect.Slice:
+		arr, ok := AsArray(el)
+		if !ok {
+			return decodeError(el, v, path)
+		}
+		v.Set(reflect.MakeSlice(v.Type(), len(arr), len(arr)))
+		return d.decodeList(arr, v, path)
+	case reflect.Array:
+		arr, ok := AsArray(el)
+		if !ok || len(arr) != v.Len() {
+			return decodeError(el, v, path)
+		}
+		return d.decodeList(arr, v, path)
+	case reflect.String:
+		str, ok := AsString(el)
+		if !ok {
+			return decodeError(el, v, path)
+		}
+		v.SetString(str)
+	case reflect.Bool:
+		b, ok := AsBool(el)
+		if !ok {
+			return decodeError(el, v, path)
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if n, ok := AsInteger(el); ok {
+			if v.OverflowInt(n) {
+				return decodeError(el, v, path)
+			}
+			v.SetInt(n)
+			break
+		}
+		n, ok := AsNumber(el)
+		if !ok || n != math.Trunc(n) || n < math.MinInt64 || n >= math.MaxInt64 || v.OverflowInt(int64(n)) {
+			return decodeError(el, v, path)
+		}
+		v.SetInt(int64(n))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if n, ok := AsInteger(el); ok {
+			if n < 0 || v.OverflowUint(uint64(n)) {
+				return decodeError(el, v, path)
+			}
+			v.SetUint(uint64(n))
+			break
+		}
+		n, ok := AsNumber(el)
+		if !ok || n != math.Trunc(n) || n < 0 || n >= math.MaxUint64 || v.OverflowUint(uint64(n)) {
+			return decodeError(el, v, path)
+		}
+		v.SetUint(uint64(n))
+	case reflect.Float32, reflect.Float64:
+		n, ok := AsNumber(el)
+		if !ok || v.OverflowFloat(n) {
+			return decodeError(el, v, path)
+		}
+		v.SetFloat(n)
+	default:
+		return decodeError(el, v, path)
+	}
+	return nil
+}
+
+// members returns the members of an object, the values of a MultiObject
+// being gathered in an array under their key.
+func members(el Element) (Object, bool) {
+	if m, ok := el.(MultiObject); ok {
+		return m.lists(), true
+	}
+	return AsObject(el)
+}
+
+func (d decoder) decodeList(arr Array, v reflect.Value, path []string) error {
+	for i := range arr {
+		if err := d.decodeValue(arr[i], v.Index(i), append(path, strconv.Itoa(i))); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (d decoder) decodeStruct(obj Object, v reflect.Value, path []string) error {
+	for k, e := range obj {
+		f, ok := fieldByKey(v, k)
+		if !ok {
+			if d.disallow {
+				return unknownField(k, path)
+			}
+			continue
+		}
+		if err := d.decodeValue(e, f, append(path, k)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fieldByKey returns the exported field of v named key by its json tag or,
+// failing that, by its Go name compared without case.
+func fieldByKey(v reflect.Value, key string) (reflect.Value, bool) {
+	var (
+		typ  = v.Type()
+		fold = -1
+	)
+	for i := 0; i < typ.NumField(); i++ {
+		f := typ.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		if name == key {
+			return v.Field(i), true
+		}
+		if fold < 0 && strings.EqualFold(name, key) {
+			fold = i
+		}
+	}
+	if fold < 0 {
+		return reflect.Value{}, false
+	}
+	return v.Field(fold), true
+}
+
+func unknownField(key string, path []string) error {
+	return fmt.Errorf("decode: unknown field %q at %q", key, joinPointer(path))
+}
+
+func decodeError(el Element, v reflect.Value, path []string) error {
+	return fmt.Errorf("decode: can not decode %s into %s at %q", el.Type(), v.Type(), joinPointer(path))
+}
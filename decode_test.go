@@ -0,0 +1,259 @@
+package saj
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type decodeUser struct {
+	Name    string            `json:"name"`
+	Age     int               `json:"age,omitempty"`
+	Admin   bool              `json:"admin"`
+	Score   float64           `json:"score"`
+	Tags    []string          `json:"tags"`
+	Labels  map[string]string `json:"labels"`
+	Parent  *decodeUser       `json:"parent"`
+	Extra   any               `json:"extra"`
+	Raw     Element           `json:"raw"`
+	Ignored string            `json:"-"`
+	Email   string
+	private string
+}
+
+func TestDecode(t *testing.T) {
+	const input = `{
+		"name": "foo",
+		"age": 42,
+		"admin": true,
+		"score": 1.5,
+		"tags": ["a", "b"],
+		"labels": {"env": "prod"},
+		"parent": {"name": "bar", "parent": null},
+		"extra": {"list": [1, "x"]},
+		"raw": [true],
+		"Ignored": "nope",
+		"email": "foo@example.com",
+		"private": "nope",
+		"unknown": 0
+	}`
+	var u decodeUser
+	if err := New(strings.NewReader(input)).Decode(&u); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	want := decodeUser{
+		Name:   "foo",
+		Age:    42,
+		Admin:  true,
+		Score:  1.5,
+		Tags:   []string{"a", "b"},
+		Labels: map[string]string{"env": "prod"},
+		Parent: &decodeUser{Name: "bar"},
+		Extra:  map[string]any{"list": []any{1.0, "x"}},
+		Raw:    Array{Literal[bool]{Literal: true}},
+		Email:  "foo@example.com",
+	}
+	if !reflect.DeepEqual(want, u) {
+		t.Errorf("want %+v, got %+v", want, u)
+	}
+}
+
+func TestDecode_Error(t *testing.T) {
+	data := []struct {
+		Input string
+		Value any
+	}{
+		{Input: `{"name": 1}`, Value: &decodeUser{}},
+		{Input: `{"age": 1.5}`, Value: &decodeUser{}},
+		{Input: `{"tags": [1]}`, Value: &decodeUser{}},
+		{Input: `[1, 2]`, Value: &decodeUser{}},
+		{Input: `300`, Value: new(int8)},
+		{Input: `-1`, Value: new(uint)},
+		{Input: `[1, 2, 3]`, Value: new([2]int)},
+		{Input: `"foo"`, Value: decodeUser{}},
+		{Input: `"foo"`, Value: nil},
+	}
+	for _, d := range data {
+		el, _ := New(strings.NewReader(d.Input)).Read()
+		if err := Decode(el, d.Value); err == nil {
+			t.Errorf("%s: invalid decoding into %T", d.Input, d.Value)
+		}
+	}
+}
+
+func TestDecodeArray(t *testing.T) {
+	const input = `[{"name": "foo", "age": 1}, {"name": "bar", "age": 2}]`
+	list, err := DecodeArray[decodeUser](New(strings.NewReader(input)))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	want := []decodeUser{{Name: "foo", Age: 1}, {Name: "bar", Age: 2}}
+	if !reflect.DeepEqual(want, list) {
+		t.Errorf("want %+v, got %+v", want, list)
+	}
+	nums, err := DecodeArray[int](New(strings.NewReader(`[1, 2, 3]`)))
+	if err != nil || !reflect.DeepEqual(nums, []int{1, 2, 3}) {
+		t.Errorf("unexpected result: %v (%v)", nums, err)
+	}
+	if _, err := DecodeArray[int](New(strings.NewReader(`{"a": 1}`))); err == nil {
+		t.Errorf("object decoded as array")
+	}
+	if _, err := DecodeArray[int](New(strings.NewReader(`[1, "two"]`))); err == nil {
+		t.Errorf("string decoded as int")
+	}
+}
+
+func TestReader_DecodeStream(t *testing.T) {
+	const input = `{
+		"name": "foo",
+		"skip": {"deep": [1, {"a": "}"}], "more": null},
+		"age": 42,
+		"tags": ["a", "b"],
+		"parent": {"name": "bar", "parent": null, "unknown": [[]]},
+		"extra": {"list": [1, "x"]},
+		"raw": [true],
+		"email": "foo@example.com"
+	}`
+	var u decodeUser
+	if err := New(strings.NewReader(input)).DecodeStream(&u); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	want := decodeUser{
+		Name:   "foo",
+		Age:    42,
+		Tags:   []string{"a", "b"},
+		Parent: &decodeUser{Name: "bar"},
+		Extra:  map[string]any{"list": []any{1.0, "x"}},
+		Raw:    Array{Literal[bool]{Literal: true}},
+		Email:  "foo@example.com",
+	}
+	if !reflect.DeepEqual(u, want) {
+		t.Errorf("want %+v, got %+v", want, u)
+	}
+
+	var list []decodeUser
+	if err := New(strings.NewReader(`[{"name": "a"}, {"name": "b"}]`)).DecodeStream(&list); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(list) != 2 || list[1].Name != "b" {
+		t.Errorf("unexpected list: %+v", list)
+	}
+
+	data := []string{
+		`{"parent": {"age": "old"}}`,
+		`{"name": "foo", "skip": [1, }`,
+		`{"name": "foo" "age": 1}`,
+		`{"name": "foo",}`,
+		`[1, 2]`,
+	}
+	for _, d := range data {
+		var u decodeUser
+		if err := New(strings.NewReader(d)).DecodeStream(&u); err == nil {
+			t.Errorf("%s: expected error but got none", d)
+		}
+	}
+	if err := New(strings.NewReader(`{"parent": {"age": "old"}}`)).DecodeStream(&u); err == nil || !strings.Contains(err.Error(), `"/parent/age"`) {
+		t.Errorf("error should give the path of the field, got %v", err)
+	}
+}
+
+func TestReader_DisallowUnknownFields(t *testing.T) {
+	data := []struct {
+		Input string
+		Err   string
+	}{
+		{Input: `{"name": "foo", "email": "foo@example.com"}`},
+		{Input: `{"name": "foo", "nmae": "bar"}`, Err: `unknown field "nmae" at ""`},
+		{Input: `{"parent": {"name": "bar", "agee": 1}}`, Err: `unknown field "agee" at "/parent"`},
+		{Input: `{"extra": {"anything": true}, "labels": {"env": "prod"}}`},
+	}
+	for _, d := range data {
+		decode := map[string]func(*Reader, any) error{
+			"decode": (*Reader).Decode,
+			"stream": (*Reader).DecodeStream,
+		}
+		for name, fn := range decode {
+			var u decodeUser
+			err := fn(NewWithOptions(strings.NewReader(d.Input), WithDisallowUnknownFields()), &u)
+			if d.Err == "" {
+				if err != nil {
+					t.Errorf("%s(%s): unexpected error: %s", d.Input, name, err)
+				}
+				continue
+			}
+			if err == nil || !strings.Contains(err.Error(), d.Err) {
+				t.Errorf("%s(%s): want error %q, got %v", d.Input, name, d.Err, err)
+			}
+		}
+	}
+
+	var u decodeUser
+	if err := New(strings.NewReader(`{"nmae": "bar"}`)).Decode(&u); err != nil {
+		t.Errorf("unknown fields should be ignored by default: %s", err)
+	}
+}
+
+func TestDecodeFile(t *testing.T) {
+	el, err := DecodeFile(filepath.Join("data", "menu.json"))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if !IsObject(el) {
+		t.Errorf("object expected, got %s", el.Type())
+	}
+
+	dir := t.TempDir()
+	if _, err := DecodeFile(filepath.Join(dir, "missing.json")); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("expected not exist error, got %v", err)
+	}
+	data := []struct {
+		Input string
+		Opts  []Option
+		Err   bool
+	}{
+		{Input: "[1, 2]\n"},
+		{Input: "[1, 2,]", Err: true},
+		{Input: "[1, 2,]", Opts: []Option{WithTrailingCommas()}},
+		{Input: "[1, 2] [3]", Err: true},
+	}
+	for i, d := range data {
+		file := filepath.Join(dir, fmt.Sprintf("%d.json", i))
+		if err := os.WriteFile(file, []byte(d.Input), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		_, err := DecodeFile(file, d.Opts...)
+		if d.Err && err == nil {
+			t.Errorf("%s: expected error but got none", d.Input)
+		} else if !d.Err && err != nil {
+			t.Errorf("%s: unexpected error: %s", d.Input, err)
+		}
+	}
+}
+
+func TestDecode_MultiObject(t *testing.T) {
+	const input = `{"name": "foo", "tags": "a", "tags": "b"}`
+
+	var m map[string]any
+	if err := NewWithOptions(strings.NewReader(input), WithMultiObjects()).Decode(&m); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	want := map[string]any{"name": []any{"foo"}, "tags": []any{"a", "b"}}
+	if !reflect.DeepEqual(m, want) {
+		t.Errorf("want %v, got %v", want, m)
+	}
+
+	var v struct {
+		Tags []string `json:"tags"`
+	}
+	if err := NewWithOptions(strings.NewReader(input), WithMultiObjects()).Decode(&v); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if !reflect.DeepEqual(v.Tags, []string{"a", "b"}) {
+		t.Errorf("want all tags, got %v", v.Tags)
+	}
+}
@@ -0,0 +1,401 @@
+package saj
+
+import (
+	"bufio"
+	"encoding"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+type Encoder struct {
+	w *bufio.Writer
+
+	html   bool
+	ascii  bool
+	sorted bool
+
+	format byte
+	prec   int
+
+	trivia map[string]Trivia
+	path   []string
+}
+
+func NewEncoder(w io.Writer) *Encoder {
+	return &Encoder{
+		w:    bufio.NewWriter(w),
+		html: true,
+	}
+}
+
+func (e *Encoder) SetEscapeHTML(escape bool) {
+	e.html = escape
+}
+
+func (e *Encoder) SetASCIIOnly(ascii bool) {
+	e.ascii = ascii
+}
+
+// SetFloatFormat controls how numbers without their original text are
+// written, with the same meaning as the fmt and prec arguments of
+// strconv.FormatFloat. Only the 'e', 'E', 'f', 'g' and 'G' formats produce
+// valid JSON.
+func (e *Encoder) SetFloatFormat(format byte, prec int) {
+	e.format = format
+	e.prec = prec
+}
+
+// SetSortKeys writes the keys of each object in lexicographic order, even
+// when the trivia given to SetTrivia records another order.
+func (e *Encoder) SetSortKeys(sort bool) {
+	e.sorted = sort
+}
+
+func (e *Encoder) SetTrivia(trivia map[string]Trivia) {
+	e.trivia = trivia
+}
+
+func (e *Encoder) Encode(el Element) error {
+	t := e.lookup()
+	e.w.WriteString(t.Before)
+	if err := e.encode(el); err != nil {
+		return err
+	}
+	e.w.WriteString(t.After)
+	return e.w.Flush()
+}
+
+func (e *Encoder) EncodeArrayStream(next func() (Element, bool, error)) error {
+	e.w.WriteByte(lsquare)
+	for i := 0; ; i++ {
+		el, ok, err := next()
+		if err != nil {
+			return err
+		}
+		if !ok {
+			break
+		}
+		if i > 0 {
+			e.w.WriteByte(comma)
+		}
+		if err := e.encode(el); err != nil {
+			return err
+		}
+	}
+	e.w.WriteByte(rsquare)
+	return e.w.Flush()
+}
+
+func (e *Encoder) encode(el Element) error {
+	if raw, ok := e.rawText(el); ok {
+		e.w.WriteString(raw)
+		return nil
+	}
+	switch el := plain(el).(type) {
+	case nil:
+		e.w.WriteString(kwNull)
+	case Object:
+		return e.encodeObject(el)
+	case MultiObject:
+		return e.encodeMulti(el)
+	case Array:
+		return e.encodeArray(el)
+	case Literal[string]:
+		e.encodeString(el.Literal)
+	case Literal[float64]:
+		return e.encodeNumber(el.Literal)
+	case Literal[int64]:
+		e.w.WriteString(strconv.FormatInt(el.Literal, 10))
+	case Literal[bool]:
+		e.w.WriteString(strconv.FormatBool(el.Literal))
+	case Literal[struct{}]:
+		e.w.WriteString(kwNull)
+	case textElement:
+		return e.encodeText(el)
+	default:
+		return fmt.Errorf("encode: unsupported element %T", el)
+	}
+	return nil
+}
+
+// textElement is implemented by elements produced outside of this package,
+// e.g. by a number parser set with SetNumberParser.
+type textElement interface {
+	Element
+	encoding.TextMarshaler
+}
+
+func (e *Encoder) encodeText(el textElement) error {
+	text, err := el.MarshalText()
+	if err != nil {
+		return fmt.Errorf("encode: %w", err)
+	}
+	switch el.Type() {
+	case TypeNumber, TypeInteger:
+		e.w.Write(text)
+	case TypeString:
+		e.encodeString(string(text))
+	default:
+		return fmt.Errorf("encode: unsupported element %T", el)
+	}
+	return nil
+}
+
+// rawText returns the original text of el when it can be written as is: always
+// for numbers, and for strings only when they are double quoted and already
+// escaped the way encodeString would require.
+func (e *Encoder) rawText(el Element) (string, bool) {
+	i, ok := el.(interface{ Raw() string })
+	if !ok {
+		return "", false
+	}
+	raw := i.Raw()
+	if raw == "" {
+		return "", false
+	}
+	if el.Type() != TypeString {
+		return raw, true
+	}
+	return raw, len(raw) >= 2 && raw[0] == quote && e.escaped(raw[1:len(raw)-1])
+}
+
+func (e *Encoder) escaped(str string) bool {
+	for i := 0; i < len(str); {
+		c, n := utf8.DecodeRuneInString(str[i:])
+		i += n
+		switch {
+		case c == backslash:
+			if i >= len(str) || !strings.ContainsRune(`"\/bfnrtu`, rune(str[i])) {
+				return false
+			}
+			i++
+		case c < 0x20 || c == 0x7f:
+			return false
+		case c == utf8.RuneError && n == 1:
+			return false
+		case c == '\u2028' || c == '\u2029':
+			return false
+		case e.html && (c == '<' || c == '>' || c == '&'):
+			return false
+		case e.ascii && c >= utf8.RuneSelf:
+			return false
+		}
+	}
+	return true
+}
+
+func (e *Encoder) encodeObject(obj Object) error {
+	e.w.WriteByte(lcurly)
+	for i, k := range e.keys(obj) {
+		if i > 0 {
+			e.w.WriteByte(comma)
+		}
+		e.push(k)
+		t := e.lookup()
+		e.w.WriteString(t.Before)
+		e.encodeString(k)
+		e.w.WriteString(t.Key)
+		e.w.WriteByte(colon)
+		e.w.WriteString(t.Colon)
+		if err := e.encode(obj[k]); err != nil {
+			return err
+		}
+		e.w.WriteString(t.After)
+		e.pop()
+	}
+	e.w.WriteString(e.lookup().Close)
+	e.w.WriteByte(rcurly)
+	return nil
+}
+
+func (e *Encoder) encodeMulti(obj MultiObject) error {
+	e.w.WriteByte(lcurly)
+	var n int
+	for _, k := range obj.SortedKeys() {
+		for _, v := range obj[k] {
+			if n > 0 {
+				e.w.WriteByte(comma)
+			}
+			n++
+			e.encodeString(k)
+			e.w.WriteByte(colon)
+			if err := e.encode(v); err != nil {
+				return err
+			}
+		}
+	}
+	e.w.WriteByte(rcurly)
+	return nil
+}
+
+func (e *Encoder) encodeArray(arr Array) error {
+	e.w.WriteByte(lsquare)
+	for i, v := range arr {
+		if i > 0 {
+			e.w.WriteByte(comma)
+		}
+		if e.trivia != nil {
+			e.push(strconv.Itoa(i))
+		}
+		t := e.lookup()
+		e.w.WriteString(t.Before)
+		if err := e.encode(v); err != nil {
+			return err
+		}
+		e.w.WriteString(t.After)
+		e.pop()
+	}
+	e.w.WriteString(e.lookup().Close)
+	e.w.WriteByte(rsquare)
+	return nil
+}
+
+func (e *Encoder) keys(obj Object) []string {
+	if e.sorted {
+		return obj.SortedKeys()
+	}
+	keys := make([]string, 0, len(obj))
+	if e.trivia == nil {
+		for k := range obj {
+			keys = append(keys, k)
+		}
+		return keys
+	}
+	seen := make(map[string]struct{})
+	for _, k := range e.lookup().Keys {
+		if _, ok := obj[k]; ok {
+			keys = append(keys, k)
+			seen[k] = struct{}{}
+		}
+	}
+	for _, k := range obj.SortedKeys() {
+		if _, ok := seen[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	return keys
+}
+
+func (e *Encoder) lookup() Trivia {
+	if e.trivia == nil {
+		return Trivia{}
+	}
+	return e.trivia[joinPointer(e.path)]
+}
+
+func (e *Encoder) push(key string) {
+	if e.trivia != nil {
+		e.path = append(e.path, key)
+	}
+}
+
+func (e *Encoder) pop() {
+	if n := len(e.path); n > 0 && e.trivia != nil {
+		e.path = e.path[:n-1]
+	}
+}
+
+func (e *Encoder) encodeNumber(f float64) error {
+	var (
+		str string
+		err error
+	)
+	switch e.format {
+	case 0:
+		str, err = formatNumber(f)
+	case 'e', 'E', 'f', 'g', 'G':
+		if math.IsNaN(f) || math.IsInf(f, 0) {
+			err = fmt.Errorf("unsupported number %v", f)
+			break
+		}
+		str = strconv.FormatFloat(f, e.format, e.prec, 64)
+	default:
+		err = fmt.Errorf("unsupported float format %q", e.format)
+	}
+	if err != nil {
+		return fmt.Errorf("encode: %w", err)
+	}
+	e.w.WriteString(str)
+	return nil
+}
+
+func formatNumber(f float64) (string, error) {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return "", fmt.Errorf("unsupported number %v", f)
+	}
+	format := byte('f')
+	if abs := math.Abs(f); abs != 0 && (abs < 1e-6 || abs >= 1e21) {
+		format = 'e'
+	}
+	return strconv.FormatFloat(f, format, -1, 64), nil
+}
+
+const hexdigits = "0123456789abcdef"
+
+// EscapeString returns str quoted and escaped as the default Encoder would
+// write it, HTML characters included. Use an Encoder with SetEscapeHTML or
+// SetASCIIOnly to change that.
+func EscapeString(str string) string {
+	var buf strings.Builder
+	WriteEscapedString(&buf, str)
+	return buf.String()
+}
+
+func WriteEscapedString(w io.Writer, str string) error {
+	e := NewEncoder(w)
+	e.encodeString(str)
+	return e.w.Flush()
+}
+
+func (e *Encoder) encodeString(str string) {
+	e.w.WriteByte(quote)
+	for i := 0; i < len(str); {
+		c, n := utf8.DecodeRuneInString(str[i:])
+		i += n
+		switch {
+		case c == quote || c == backslash:
+			e.w.WriteByte(backslash)
+			e.w.WriteByte(byte(c))
+		case c == '\b':
+			e.w.WriteString(`\b`)
+		case c == '\f':
+			e.w.WriteString(`\f`)
+		case c == nl:
+			e.w.WriteString(`\n`)
+		case c == cr:
+			e.w.WriteString(`\r`)
+		case c == tab:
+			e.w.WriteString(`\t`)
+		case c < 0x20 || c == 0x7f:
+			e.writeUnicode(c)
+		case c == utf8.RuneError && n == 1:
+			e.w.WriteString(`\ufffd`)
+		case c == '\u2028' || c == '\u2029':
+			e.writeUnicode(c)
+		case e.html && (c == '<' || c == '>' || c == '&'):
+			e.writeUnicode(c)
+		case e.ascii && c >= utf8.RuneSelf:
+			if c1, c2 := utf16.EncodeRune(c); c1 != utf8.RuneError {
+				e.writeUnicode(c1)
+				e.writeUnicode(c2)
+			} else {
+				e.writeUnicode(c)
+			}
+		default:
+			e.w.WriteRune(c)
+		}
+	}
+	e.w.WriteByte(quote)
+}
+
+func (e *Encoder) writeUnicode(c rune) {
+	e.w.WriteString(`\u`)
+	for shift := 12; shift >= 0; shift -= 4 {
+		e.w.WriteByte(hexdigits[(c>>shift)&0xF])
+	}
+}
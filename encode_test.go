@@ -0,0 +1,513 @@
+package saj
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestEncoder(t *testing.T) {
+	data := []struct {
+		Input string
+		Want  string
+	}{
+		{Input: `"foobar"`, Want: `"foobar"`},
+		{Input: `"foo\"bar\\\n\t\u0001"`, Want: `"foo\"bar\\\n\t\u0001"`},
+		{Input: `"\u2028"`, Want: `"\u2028"`},
+		{Input: `"été"`, Want: `"été"`},
+		{Input: `-3.14`, Want: `-3.14`},
+		{Input: `1000000`, Want: `1000000`},
+		{Input: `1e21`, Want: `1e+21`},
+		{Input: `true`, Want: `true`},
+		{Input: `null`, Want: `null`},
+		{Input: `[]`, Want: `[]`},
+		{Input: `{}`, Want: `{}`},
+		{Input: `[  1 , "two" , [ null ] ]`, Want: `[1,"two",[null]]`},
+		{Input: `{ "name" : [ true, false ] }`, Want: `{"name":[true,false]}`},
+	}
+	for _, d := range data {
+		e, err := New(strings.NewReader(d.Input)).Read()
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", d.Input, err)
+			continue
+		}
+		var str strings.Builder
+		if err := NewEncoder(&str).Encode(e); err != nil {
+			t.Errorf("%s: unexpected error while encoding: %s", d.Input, err)
+			continue
+		}
+		if got := str.String(); got != d.Want {
+			t.Errorf("%s: want %s, got %s", d.Input, d.Want, got)
+		}
+	}
+}
+
+func TestEncoder_InvalidUTF8(t *testing.T) {
+	var str strings.Builder
+	if err := NewEncoder(&str).Encode(String("foo\xffbar")); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if want := `"foo\ufffdbar"`; str.String() != want {
+		t.Errorf("want %s, got %s", want, str.String())
+	}
+}
+
+func TestEncoder_Error(t *testing.T) {
+	data := []Element{
+		Literal[float64]{Literal: math.NaN()},
+		Array{Literal[float64]{Literal: math.Inf(1)}},
+	}
+	for _, d := range data {
+		var str strings.Builder
+		if err := NewEncoder(&str).Encode(d); err == nil {
+			t.Errorf("%v: unsupported value encoded as %s", d, str.String())
+		}
+	}
+}
+
+func TestEncoder_FloatFormat(t *testing.T) {
+	data := []struct {
+		Value  float64
+		Format byte
+		Prec   int
+		Want   string
+	}{
+		{Value: 0.30000000000000004, Want: `0.30000000000000004`},
+		{Value: 1e21, Want: `1e+21`},
+		{Value: 0.30000000000000004, Format: 'g', Prec: -1, Want: `0.30000000000000004`},
+		{Value: 0.30000000000000004, Format: 'g', Prec: 3, Want: `0.3`},
+		{Value: 1e21, Format: 'g', Prec: -1, Want: `1e+21`},
+		{Value: 1e21, Format: 'f', Prec: -1, Want: `1000000000000000000000`},
+		{Value: 3.14159, Format: 'f', Prec: 2, Want: `3.14`},
+		{Value: 1234.5, Format: 'E', Prec: 1, Want: `1.2E+03`},
+	}
+	for _, d := range data {
+		var str strings.Builder
+		e := NewEncoder(&str)
+		e.SetFloatFormat(d.Format, d.Prec)
+		if err := e.Encode(Literal[float64]{Literal: d.Value}); err != nil {
+			t.Errorf("%v: unexpected error: %s", d.Value, err)
+			continue
+		}
+		if got := str.String(); got != d.Want {
+			t.Errorf("%v (%c, %d): want %s, got %s", d.Value, d.Format, d.Prec, d.Want, got)
+		}
+	}
+
+	var str strings.Builder
+	e := NewEncoder(&str)
+	e.SetFloatFormat('x', -1)
+	if err := e.Encode(Literal[float64]{Literal: 1}); err == nil {
+		t.Errorf("invalid format accepted: %s", str.String())
+	}
+	e.SetFloatFormat('g', -1)
+	if err := e.Encode(Literal[float64]{Literal: math.Inf(-1)}); err == nil {
+		t.Errorf("infinity encoded as %s", str.String())
+	}
+
+	el, err := NewWithOptions(strings.NewReader(`1.50`), WithPreserveRaw()).Read()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	str.Reset()
+	e = NewEncoder(&str)
+	e.SetFloatFormat('f', 3)
+	if err := e.Encode(el); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if want := `1.50`; str.String() != want {
+		t.Errorf("raw text not preferred: want %s, got %s", want, str.String())
+	}
+}
+
+func TestEncoder_EncodeArrayStream(t *testing.T) {
+	var (
+		str strings.Builder
+		enc = NewEncoder(&str)
+		n   int
+	)
+	err := enc.EncodeArrayStream(func() (Element, bool, error) {
+		if n >= 3 {
+			return nil, false, nil
+		}
+		n++
+		return Object{"id": Literal[float64]{Literal: float64(n)}}, true, nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if want := `[{"id":1},{"id":2},{"id":3}]`; str.String() != want {
+		t.Errorf("want %s, got %s", want, str.String())
+	}
+
+	errStop := errors.New("stop")
+	err = enc.EncodeArrayStream(func() (Element, bool, error) {
+		return nil, false, errStop
+	})
+	if !errors.Is(err, errStop) {
+		t.Errorf("expected producer error, got %v", err)
+	}
+}
+
+func TestEncoder_Escape(t *testing.T) {
+	const input = "<a href=\"#\">été & 😀</a>"
+
+	data := []struct {
+		HTML  bool
+		ASCII bool
+		Want  string
+	}{
+		{
+			HTML: true,
+			Want: `"\u003ca href=\"#\"\u003eété \u0026 😀\u003c/a\u003e"`,
+		},
+		{
+			Want: `"<a href=\"#\">été & 😀</a>"`,
+		},
+		{
+			ASCII: true,
+			Want:  `"<a href=\"#\">\u00e9t\u00e9 & \ud83d\ude00</a>"`,
+		},
+		{
+			HTML:  true,
+			ASCII: true,
+			Want:  `"\u003ca href=\"#\"\u003e\u00e9t\u00e9 \u0026 \ud83d\ude00\u003c/a\u003e"`,
+		},
+	}
+	for _, d := range data {
+		var (
+			str strings.Builder
+			enc = NewEncoder(&str)
+		)
+		enc.SetEscapeHTML(d.HTML)
+		enc.SetASCIIOnly(d.ASCII)
+		if err := enc.Encode(String(input)); err != nil {
+			t.Errorf("unexpected error: %s", err)
+			continue
+		}
+		if str.String() != d.Want {
+			t.Errorf("want %s, got %s", d.Want, str.String())
+		}
+	}
+}
+
+func TestEscapeString(t *testing.T) {
+	data := []struct {
+		Input string
+		Want  string
+	}{
+		{Input: ``, Want: `""`},
+		{Input: `foobar`, Want: `"foobar"`},
+		{Input: "a\"b\\c", Want: `"a\"b\\c"`},
+		{Input: "\b\f\n\r\t\x00\x1f", Want: `"\b\f\n\r\t\u0000\u001f"`},
+		{Input: "<a>&</a>", Want: `"\u003ca\u003e\u0026\u003c/a\u003e"`},
+		{Input: "été\u2028", Want: `"été\u2028"`},
+		{Input: "bad\xff", Want: `"bad\ufffd"`},
+	}
+	for _, d := range data {
+		got := EscapeString(d.Input)
+		if got != d.Want {
+			t.Errorf("%q: want %s, got %s", d.Input, d.Want, got)
+		}
+		var str strings.Builder
+		if err := NewEncoder(&str).Encode(String(d.Input)); err != nil || str.String() != got {
+			t.Errorf("%q: encoder wrote %s, EscapeString %s", d.Input, str.String(), got)
+		}
+		e, err := New(strings.NewReader(got)).Read()
+		if s, _ := AsString(e); err != nil || (s != d.Input && utf8.ValidString(d.Input)) {
+			t.Errorf("%q: escaped string not read back (%v)", d.Input, err)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := WriteEscapedString(&buf, "x\ty"); err != nil || buf.String() != `"x\ty"` {
+		t.Errorf("unexpected output %s (%v)", buf.String(), err)
+	}
+}
+
+func TestEscapeString_Control(t *testing.T) {
+	short := map[rune]string{'\b': `\b`, '\f': `\f`, '\n': `\n`, '\r': `\r`, '\t': `\t`}
+	for c := rune(0); c <= 0x7f; c++ {
+		if c >= 0x20 && c < 0x7f {
+			continue
+		}
+		want, ok := short[c]
+		if !ok {
+			want = fmt.Sprintf(`\u%04x`, c)
+		}
+		want = `"` + want + `"`
+		if got := EscapeString(string(c)); got != want {
+			t.Errorf("%U: want %s, got %s", c, want, got)
+		}
+		e, err := New(strings.NewReader(want)).Read()
+		if s, _ := AsString(e); err != nil || s != string(c) {
+			t.Errorf("%U: escaped character not read back (%v)", c, err)
+		}
+	}
+}
+
+func TestEncoder_Raw(t *testing.T) {
+	const input = `[1.0, 1e3, -0.50E+01, "café \/ <b>", true, null, {"n": 100}]`
+
+	e, err := NewWithOptions(strings.NewReader(input), WithPreserveRaw()).Read()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	arr, _ := AsArray(e)
+	if raw := arr[0].(RawLiteral[float64]).Raw(); raw != "1.0" {
+		t.Errorf("unexpected raw text: %s", raw)
+	}
+
+	var str strings.Builder
+	enc := NewEncoder(&str)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(e); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if want := `[1.0,1e3,-0.50E+01,"café \/ <b>",true,null,{"n":100}]`; str.String() != want {
+		t.Errorf("want %s, got %s", want, str.String())
+	}
+
+	str.Reset()
+	if err := NewEncoder(&str).Encode(arr[3]); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if want := `"café / \u003cb\u003e"`; str.String() != want {
+		t.Errorf("raw text should be escaped for html: want %s, got %s", want, str.String())
+	}
+
+	str.Reset()
+	enc = NewEncoder(&str)
+	enc.SetEscapeHTML(false)
+	enc.SetASCIIOnly(true)
+	if err := enc.Encode(arr[3]); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if want := `"caf\u00e9 / <b>"`; str.String() != want {
+		t.Errorf("raw text should be escaped to ascii: want %s, got %s", want, str.String())
+	}
+
+	arr[1], _ = Number("2000")
+	str.Reset()
+	if err := NewEncoder(&str).Encode(arr[:2]); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if want := `[1.0,2000]`; str.String() != want {
+		t.Errorf("want %s, got %s", want, str.String())
+	}
+
+	e, err = NewWithOptions(strings.NewReader(`'single'`), WithPreserveRaw(), WithSingleQuotes()).Read()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	str.Reset()
+	if err := NewEncoder(&str).Encode(e); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if want := `"single"`; str.String() != want {
+		t.Errorf("want %s, got %s", want, str.String())
+	}
+
+	e, err = NewWithOptions(strings.NewReader(`"it\'s"`), WithPreserveRaw(), WithSingleQuotes()).Read()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	str.Reset()
+	if err := NewEncoder(&str).Encode(e); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if want := `"it's"`; str.String() != want {
+		t.Errorf("want %s, got %s", want, str.String())
+	}
+}
+
+func TestEncoder_Trivia(t *testing.T) {
+	const input = `
+{
+  "name" : "foo",
+  "version": 1.0,
+  "empty": {   },
+  "tags": [ "a",
+            "b" ],
+  "nested": {"enabled":true  , "level": 3}
+}
+`
+	r := NewWithOptions(strings.NewReader(input), WithPreserveFormat(), WithPreserveRaw())
+	e, err := r.Read()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	var str strings.Builder
+	enc := NewEncoder(&str)
+	enc.SetTrivia(r.Trivia())
+	if err := enc.Encode(e); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if str.String() != input {
+		t.Errorf("layout not preserved: want %q, got %q", input, str.String())
+	}
+
+	obj, _ := AsObject(e)
+	nested, _ := AsObject(obj["nested"])
+	nested["level"], _ = Number("4")
+	nested["debug"] = Literal[bool]{Literal: false}
+	delete(obj, "empty")
+
+	str.Reset()
+	if err := enc.Encode(e); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	want := strings.Replace(input, `"level": 3}`, `"level": 4,"debug":false}`, 1)
+	want = strings.Replace(want, "\n  \"empty\": {   },", "", 1)
+	if str.String() != want {
+		t.Errorf("layout not preserved after edit: want %q, got %q", want, str.String())
+	}
+}
+
+func TestEncoder_SortKeys(t *testing.T) {
+	const (
+		input = `{"b": {"z": 1, "y": [{"d": 2, "c": 3}]}, "a": true}`
+		want  = `{"a":true,"b":{"y":[{"c":3,"d":2}],"z":1}}`
+	)
+	for _, preserve := range []bool{false, true} {
+		r := New(strings.NewReader(input))
+		r.SetPreserveFormat(preserve)
+		e, err := r.Read()
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		var str strings.Builder
+		enc := NewEncoder(&str)
+		enc.SetSortKeys(true)
+		if preserve {
+			enc.SetTrivia(r.Trivia())
+		}
+		if err := enc.Encode(e); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		got := str.String()
+		if preserve {
+			got = strings.ReplaceAll(got, " ", "")
+		}
+		if got != want {
+			t.Errorf("keys not sorted (preserve: %t): want %s, got %s", preserve, want, got)
+		}
+	}
+}
+
+func TestEncoder_Comments(t *testing.T) {
+	const input = `// settings
+{
+  /* server */
+  "host": "localhost", // default host
+  "ports": [
+    80, // http
+    443 /* https */
+  ],
+  "debug" /* key */ : false
+  // end
+}
+`
+	r := NewWithOptions(strings.NewReader(input), WithPreserveComments())
+	e, err := r.Read()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	var str strings.Builder
+	enc := NewEncoder(&str)
+	enc.SetTrivia(r.Trivia())
+	if err := enc.Encode(e); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if str.String() != input {
+		t.Errorf("comments not preserved: want %q, got %q", input, str.String())
+	}
+
+	data := map[string][]string{
+		"":         {"// settings"},
+		"/host":    {"/* server */"},
+		"/ports":   {"// default host"},
+		"/ports/0": nil,
+		"/ports/1": {"// http"},
+		"/debug":   {"/* key */"},
+	}
+	trivia := r.Trivia()
+	for ptr, want := range data {
+		if got := trivia[ptr].Comments; !reflect.DeepEqual(got, want) {
+			t.Errorf("%q: want comments %q, got %q", ptr, want, got)
+		}
+	}
+
+	r = NewWithOptions(strings.NewReader(input), WithComments(), WithPreserveFormat())
+	if _, err := r.Read(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if c := r.Trivia()["/host"].Comments; c != nil {
+		t.Errorf("comments kept without option: %q", c)
+	}
+}
+
+type decimal struct {
+	units int64
+	scale int
+}
+
+func (_ decimal) Type() ElementType {
+	return TypeNumber
+}
+
+func (d decimal) MarshalText() ([]byte, error) {
+	str := strconv.FormatInt(d.units, 10)
+	if d.scale == 0 {
+		return []byte(str), nil
+	}
+	for len(str) <= d.scale {
+		str = "0" + str
+	}
+	return []byte(str[:len(str)-d.scale] + "." + str[len(str)-d.scale:]), nil
+}
+
+func parseDecimal(raw string) (Element, error) {
+	var d decimal
+	if x := strings.IndexByte(raw, '.'); x >= 0 {
+		d.scale = len(raw) - x - 1
+		raw = raw[:x] + raw[x+1:]
+	}
+	n, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("decimal: %w", err)
+	}
+	d.units = n
+	return d, nil
+}
+
+func TestReader_NumberParser(t *testing.T) {
+	const input = `{"price": 19.99, "qty": 3, "total": 59.97}`
+
+	e, err := NewWithOptions(strings.NewReader(input), WithNumberParser(parseDecimal)).Read()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	obj, _ := AsObject(e)
+	if d, ok := obj["price"].(decimal); !ok || d.units != 1999 || d.scale != 2 {
+		t.Errorf("unexpected price: %#v", obj["price"])
+	}
+	var buf bytes.Buffer
+	if err := NewEncoder(&buf).Encode(obj["total"]); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if got := buf.String(); got != "59.97" {
+		t.Errorf("want 59.97, got %s", got)
+	}
+	if _, err := NewWithOptions(strings.NewReader(`1e5`), WithNumberParser(parseDecimal)).Read(); err == nil {
+		t.Errorf("number parser error not reported")
+	}
+}
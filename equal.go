@@ -0,0 +1,337 @@
+package saj
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+func Equal(a, b Element) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	a, b = plain(a), plain(b)
+	switch a := a.(type) {
+	case Object:
+		b, ok := b.(Object)
+		if !ok || len(a) != len(b) {
+			return false
+		}
+		for k, v := range a {
+			other, ok := b[k]
+			if !ok || !Equal(v, other) {
+				return false
+			}
+		}
+		return true
+	case MultiObject:
+		b, ok := b.(MultiObject)
+		if !ok || len(a) != len(b) {
+			return false
+		}
+		for k, vs := range a {
+			other, ok := b[k]
+			if !ok || !Equal(Array(vs), Array(other)) {
+				return false
+			}
+		}
+		return true
+	case Array:
+		b, ok := b.(Array)
+		if !ok || len(a) != len(b) {
+			return false
+		}
+		for i := range a {
+			if !Equal(a[i], b[i]) {
+				return false
+			}
+		}
+		return true
+	case Literal[string]:
+		b, ok := b.(Literal[string])
+		return ok && a.Literal == b.Literal
+	case Literal[float64]:
+		n, ok := AsNumber(b)
+		return ok && a.Literal == n
+	case Literal[int64]:
+		if b, ok := b.(Literal[int64]); ok {
+			return a.Literal == b.Literal
+		}
+		n, ok := AsNumber(b)
+		return ok && float64(a.Literal) == n
+	case Literal[bool]:
+		b, ok := b.(Literal[bool])
+		return ok && a.Literal == b.Literal
+	case Literal[struct{}]:
+		return IsNull(b)
+	default:
+		return false
+	}
+}
+
+// EqualExcept is like Equal but treats the values found at the given
+// pointers as wildcards: they match whatever the other tree holds there,
+// including nothing at all. Invalid pointers are ignored.
+func EqualExcept(a, b Element, ignorePointers ...string) bool {
+	skip := make(map[string]bool)
+	for _, p := range ignorePointers {
+		if path, err := splitPointer(p); err == nil {
+			skip[joinPointer(path)] = true
+		}
+	}
+	return equalExcept(a, b, nil, skip)
+}
+
+func equalExcept(a, b Element, path []string, skip map[string]bool) bool {
+	if skip[joinPointer(path)] {
+		return true
+	}
+	switch a := a.(type) {
+	case Object:
+		b, ok := b.(Object)
+		if !ok {
+			return false
+		}
+		for k, v := range a {
+			other, ok := b[k]
+			if !ok {
+				if skip[joinPointer(append(path, k))] {
+					continue
+				}
+				return false
+			}
+			if !equalExcept(v, other, append(path, k), skip) {
+				return false
+			}
+		}
+		for k := range b {
+			if _, ok := a[k]; !ok && !skip[joinPointer(append(path, k))] {
+				return false
+			}
+		}
+		return true
+	case MultiObject:
+		b, ok := b.(MultiObject)
+		if !ok {
+			return false
+		}
+		return equalExcept(a.lists(), b.lists(), path, skip)
+	case Array:
+		b, ok := b.(Array)
+		if !ok || len(a) != len(b) {
+			return false
+		}
+		for i := range a {
+			if !equalExcept(a[i], b[i], append(path, strconv.Itoa(i)), skip) {
+				return false
+			}
+		}
+		return true
+	default:
+		return Equal(a, b)
+	}
+}
+
+type MismatchError struct {
+	Path string
+}
+
+func (e MismatchError) Error() string {
+	return fmt.Sprintf("documents differ at %q", e.Path)
+}
+
+// StreamEqual reports whether a and b hold the same value. Arrays are
+// compared element by element as they are read; objects of a are read in
+// full and matched against the members of b as they come. When the values
+// differ, the returned error is a MismatchError giving the path of the
+// first difference found.
+func StreamEqual(a, b io.Reader, opts ...Option) (bool, error) {
+	var (
+		ra = NewWithOptions(a, opts...)
+		rb = NewWithOptions(b, opts...)
+	)
+	if err := streamEqual(ra, rb, nil); err != nil {
+		return false, err
+	}
+	for _, r := range []*Reader{ra, rb} {
+		if c, err := r.next(); err == nil {
+			return false, fmt.Errorf("equal: unexpected character %c after value", c)
+		}
+	}
+	return true, nil
+}
+
+func streamEqual(ra, rb *Reader, path []string) error {
+	ta, err := ra.Peek()
+	if err != nil {
+		return err
+	}
+	tb, err := rb.Peek()
+	if err != nil {
+		return err
+	}
+	if ta != tb {
+		return MismatchError{Path: joinPointer(path)}
+	}
+	switch ta {
+	case TypeObject:
+		el, err := ra.read()
+		if err != nil {
+			return err
+		}
+		return elementEqual(el, rb, path)
+	case TypeArray:
+		ra.next()
+		rb.next()
+		if err := ra.enter(); err != nil {
+			return err
+		}
+		defer ra.leave()
+		if err := rb.enter(); err != nil {
+			return err
+		}
+		defer rb.leave()
+		for i := 0; ; i++ {
+			ma, err := ra.elem(rsquare, i)
+			if err != nil {
+				return err
+			}
+			mb, err := rb.elem(rsquare, i)
+			if err != nil {
+				return err
+			}
+			if ma != mb {
+				return MismatchError{Path: joinPointer(path)}
+			}
+			if !ma {
+				ra.skipBlank()
+				rb.skipBlank()
+				return nil
+			}
+			if err := streamEqual(ra, rb, append(path, strconv.Itoa(i))); err != nil {
+				return err
+			}
+		}
+	default:
+		ea, err := ra.read()
+		if err != nil {
+			return err
+		}
+		eb, err := rb.read()
+		if err != nil {
+			return err
+		}
+		if !Equal(ea, eb) {
+			return MismatchError{Path: joinPointer(path)}
+		}
+		return nil
+	}
+}
+
+func elementEqual(el Element, rb *Reader, path []string) error {
+	t, err := rb.Peek()
+	if err != nil {
+		return err
+	}
+	if !t.accepts(el.Type()) {
+		return MismatchError{Path: joinPointer(path)}
+	}
+	switch el := el.(type) {
+	case Object:
+		rb.next()
+		if err := rb.enter(); err != nil {
+			return err
+		}
+		defer rb.leave()
+		seen := make(map[string]struct{}, len(el))
+		for i := 0; ; i++ {
+			more, err := rb.elem(rcurly, i)
+			if err != nil {
+				return err
+			}
+			if !more {
+				break
+			}
+			key, err := rb.key()
+			if err != nil {
+				return err
+			}
+			child, ok := el[key]
+			if !ok {
+				return MismatchError{Path: joinPointer(append(path, key))}
+			}
+			seen[key] = struct{}{}
+			if err := elementEqual(child, rb, append(path, key)); err != nil {
+				return err
+			}
+		}
+		rb.skipBlank()
+		for _, k := range el.SortedKeys() {
+			if _, ok := seen[k]; !ok {
+				return MismatchError{Path: joinPointer(append(path, k))}
+			}
+		}
+		return nil
+	case Array:
+		rb.next()
+		if err := rb.enter(); err != nil {
+			return err
+		}
+		defer rb.leave()
+		for i := 0; ; i++ {
+			more, err := rb.elem(rsquare, i)
+			if err != nil {
+				return err
+			}
+			if more != (i < len(el)) {
+				return MismatchError{Path: joinPointer(path)}
+			}
+			if !more {
+				rb.skipBlank()
+				return nil
+			}
+			if err := elementEqual(el[i], rb, append(path, strconv.Itoa(i))); err != nil {
+				return err
+			}
+		}
+	default:
+		other, err := rb.read()
+		if err != nil {
+			return err
+		}
+		if !Equal(el, other) {
+			return MismatchError{Path: joinPointer(path)}
+		}
+		return nil
+	}
+}
+
+// elem reads the separator in front of the i-th member of the container
+// closed by end and reports whether such a member follows.
+func (r *Reader) elem(end rune, i int) (bool, error) {
+	r.skipBlank()
+	c, err := r.next()
+	if err != nil {
+		return false, err
+	}
+	if c == end {
+		return false, nil
+	}
+	if i > 0 {
+		if c != comma {
+			return false, fmt.Errorf("unexpected character %c", c)
+		}
+		r.skipBlank()
+		c, err = r.next()
+		if err != nil && !errors.Is(err, io.EOF) {
+			return false, err
+		} else if c == end && r.trailing {
+			return false, nil
+		} else if c == end || err != nil {
+			return false, fmt.Errorf("unexpected ',' before '%c'", end)
+		}
+	}
+	r.reset()
+	return true, nil
+}
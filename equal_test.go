@@ -0,0 +1,151 @@
+package saj
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestEqual(t *testing.T) {
+	data := []struct {
+		Left  string
+		Right string
+		Equal bool
+	}{
+		{Left: `"foo"`, Right: `"foo"`, Equal: true},
+		{Left: `"foo"`, Right: `"bar"`},
+		{Left: `1.0`, Right: `1`, Equal: true},
+		{Left: `1e2`, Right: `100`, Equal: true},
+		{Left: `1`, Right: `"1"`},
+		{Left: `null`, Right: `null`, Equal: true},
+		{Left: `null`, Right: `false`},
+		{Left: `[1, 2]`, Right: `[1, 2]`, Equal: true},
+		{Left: `[1, 2]`, Right: `[2, 1]`},
+		{Left: `[1, 2]`, Right: `[1, 2, 3]`},
+		{Left: `{"a": 1, "b": [true]}`, Right: `{"b": [true], "a": 1}`, Equal: true},
+		{Left: `{"a": 1}`, Right: `{"a": 1, "b": 2}`},
+		{Left: `{"a": 1}`, Right: `{"b": 1}`},
+		{Left: `{}`, Right: `[]`},
+	}
+	for _, d := range data {
+		left, err := New(strings.NewReader(d.Left)).Read()
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %s", d.Left, err)
+		}
+		right, err := New(strings.NewReader(d.Right)).Read()
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %s", d.Right, err)
+		}
+		if got := Equal(left, right); got != d.Equal {
+			t.Errorf("%s == %s: want %t, got %t", d.Left, d.Right, d.Equal, got)
+		}
+	}
+}
+
+func TestEqualExcept(t *testing.T) {
+	data := []struct {
+		Left   string
+		Right  string
+		Ignore []string
+		Equal  bool
+	}{
+		{Left: `{"id": 1, "name": "foo"}`, Right: `{"id": 2, "name": "foo"}`, Ignore: []string{"/id"}, Equal: true},
+		{Left: `{"id": 1, "name": "foo"}`, Right: `{"id": 2, "name": "bar"}`, Ignore: []string{"/id"}},
+		{Left: `{"id": 1, "name": "foo"}`, Right: `{"name": "foo"}`, Ignore: []string{"/id"}, Equal: true},
+		{Left: `{"name": "foo"}`, Right: `{"id": {"a": 1}, "name": "foo"}`, Ignore: []string{"/id"}, Equal: true},
+		{Left: `{"id": 1}`, Right: `{"id": 2}`},
+		{Left: `[{"ts": 1, "v": true}, {"ts": 2, "v": false}]`, Right: `[{"ts": 3, "v": true}, {"ts": 4, "v": false}]`, Ignore: []string{"/0/ts", "/1/ts"}, Equal: true},
+		{Left: `[{"ts": 1}, {"ts": 2}]`, Right: `[{"ts": 3}, {"ts": 4}]`, Ignore: []string{"/0/ts"}},
+		{Left: `[1, 2]`, Right: `[1, 3]`, Ignore: []string{"/1"}, Equal: true},
+		{Left: `[1, 2]`, Right: `[1, 2, 3]`, Ignore: []string{"/2"}},
+		{Left: `{"a/b": 1}`, Right: `{"a/b": 2}`, Ignore: []string{"/a~1b"}, Equal: true},
+		{Left: `{"a": 1}`, Right: `[1]`, Ignore: []string{""}, Equal: true},
+		{Left: `{"a": 1}`, Right: `{"a": 2}`, Ignore: []string{"a"}},
+	}
+	for _, d := range data {
+		left, err := New(strings.NewReader(d.Left)).Read()
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %s", d.Left, err)
+		}
+		right, err := New(strings.NewReader(d.Right)).Read()
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %s", d.Right, err)
+		}
+		if got := EqualExcept(left, right, d.Ignore...); got != d.Equal {
+			t.Errorf("%s == %s (except %q): want %t, got %t", d.Left, d.Right, d.Ignore, d.Equal, got)
+		}
+	}
+}
+
+func TestEqualExcept_MultiObject(t *testing.T) {
+	var (
+		left  = MultiObject{"id": {IntFrom(1), IntFrom(2)}, "name": {String("foo")}}
+		right = MultiObject{"id": {IntFrom(3), IntFrom(2)}, "name": {String("foo")}}
+	)
+	if EqualExcept(left, right) {
+		t.Errorf("different multi objects reported equal")
+	}
+	if !EqualExcept(left, right, "/id/0") {
+		t.Errorf("ignored value not skipped in multi object")
+	}
+	if EqualExcept(left, right, "/id/1") {
+		t.Errorf("multi objects reported equal while /id/0 differ")
+	}
+	if !EqualExcept(left, right, "/id") {
+		t.Errorf("ignored key not skipped in multi object")
+	}
+}
+
+func TestStreamEqual(t *testing.T) {
+	data := []struct {
+		Left  string
+		Right string
+		Path  string
+		Equal bool
+	}{
+		{Left: `"foo"`, Right: ` "foo" `, Equal: true},
+		{Left: `1.0`, Right: `1`, Equal: true},
+		{Left: `[1, [2, {"a": 3}]]`, Right: `[1,[2,{"a":3}]]`, Equal: true},
+		{Left: `{"a": 1, "b": [true, null]}`, Right: `{"b": [true, null], "a": 1}`, Equal: true},
+		{Left: `{}`, Right: `{}`, Equal: true},
+		{Left: `[]`, Right: `[]`, Equal: true},
+		{Left: `"foo"`, Right: `"bar"`, Path: ""},
+		{Left: `[1, 2]`, Right: `[1, 2, 3]`, Path: ""},
+		{Left: `[1, 2, 3]`, Right: `[1, 2]`, Path: ""},
+		{Left: `[1, [2, 3]]`, Right: `[1, [2, 4]]`, Path: "/1/1"},
+		{Left: `{"a": {"b": 1}}`, Right: `{"a": {"b": "1"}}`, Path: "/a/b"},
+		{Left: `{"a": 1}`, Right: `{"a": 1, "b": 2}`, Path: "/b"},
+		{Left: `{"a": 1, "b": 2}`, Right: `{"a": 1}`, Path: "/b"},
+		{Left: `[{"a": [1]}]`, Right: `[{"a": [1, 2]}]`, Path: "/0/a"},
+		{Left: `{}`, Right: `[]`, Path: ""},
+	}
+	for _, d := range data {
+		for name, rs := range readers(d.Right) {
+			ok, err := StreamEqual(strings.NewReader(d.Left), rs)
+			if ok != d.Equal {
+				t.Errorf("%s == %s(%s): want %t, got %t (%v)", d.Left, d.Right, name, d.Equal, ok, err)
+				continue
+			}
+			if d.Equal {
+				if err != nil {
+					t.Errorf("%s == %s(%s): unexpected error: %s", d.Left, d.Right, name, err)
+				}
+				continue
+			}
+			var m MismatchError
+			if !errors.As(err, &m) {
+				t.Errorf("%s == %s(%s): expected mismatch, got %v", d.Left, d.Right, name, err)
+			} else if m.Path != d.Path {
+				t.Errorf("%s == %s(%s): want path %q, got %q", d.Left, d.Right, name, d.Path, m.Path)
+			}
+		}
+	}
+	invalid := []string{`[1, 2`, `{"a" 1}`, `[1 2]`, `1 2`}
+	for _, str := range invalid {
+		ok, err := StreamEqual(strings.NewReader(str), strings.NewReader(str))
+		var m MismatchError
+		if ok || err == nil || errors.As(err, &m) {
+			t.Errorf("%s: expected syntax error, got %t (%v)", str, ok, err)
+		}
+	}
+}
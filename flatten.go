@@ -0,0 +1,138 @@
+package saj
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+func Flatten(el Element) (map[string]Element, error) {
+	switch el.(type) {
+	case Object, MultiObject, Array:
+	default:
+		return nil, fmt.Errorf("flatten: expected object or array, got %s", el.Type())
+	}
+	list := make(map[string]Element)
+	return list, flatten(list, "", el)
+}
+
+func flatten(list map[string]Element, prefix string, el Element) error {
+	join := func(key string) string {
+		if prefix == "" {
+			return key
+		}
+		return prefix + "." + key
+	}
+	switch el := el.(type) {
+	case MultiObject:
+		return flatten(list, prefix, el.lists())
+	case Object:
+		if len(el) == 0 && prefix != "" {
+			list[prefix] = el
+		}
+		for k, v := range el {
+			if strings.Contains(k, ".") {
+				return fmt.Errorf("flatten: key %q contains a dot", k)
+			}
+			if err := flatten(list, join(k), v); err != nil {
+				return err
+			}
+		}
+	case Array:
+		if len(el) == 0 && prefix != "" {
+			list[prefix] = el
+		}
+		for i, v := range el {
+			if err := flatten(list, join(strconv.Itoa(i)), v); err != nil {
+				return err
+			}
+		}
+	default:
+		list[prefix] = el
+	}
+	return nil
+}
+
+// Unflatten rebuilds the document flattened by Flatten. The objects found in
+// list are copied before values are added to them, so list is left unchanged.
+func Unflatten(list map[string]Element) (Element, error) {
+	var (
+		root = make(Object)
+		made = map[string]bool{"": true}
+	)
+	for k, v := range list {
+		var (
+			parts = strings.Split(k, ".")
+			obj   = root
+		)
+		for i, p := range parts[:len(parts)-1] {
+			path := strings.Join(parts[:i+1], ".")
+			switch curr := obj[p].(type) {
+			case nil:
+				sub := make(Object)
+				obj[p], made[path] = sub, true
+				obj = sub
+			case Object:
+				if !made[path] {
+					curr = copyObject(curr)
+					obj[p], made[path] = curr, true
+				}
+				obj = curr
+			default:
+				return nil, fmt.Errorf("unflatten: %s: conflict with scalar value", path)
+			}
+		}
+		last := parts[len(parts)-1]
+		curr, ok := obj[last]
+		if !ok {
+			obj[last] = v
+			continue
+		}
+		sub, isObj := curr.(Object)
+		leaf, isLeaf := v.(Object)
+		if !isObj || !isLeaf {
+			return nil, fmt.Errorf("unflatten: %s: conflict with nested values", k)
+		}
+		for lk, lv := range leaf {
+			if _, ok := sub[lk]; ok {
+				return nil, fmt.Errorf("unflatten: %s.%s: conflict with nested values", k, lk)
+			}
+			sub[lk] = lv
+		}
+	}
+	return unflatten(root, "", made), nil
+}
+
+func copyObject(obj Object) Object {
+	cp := make(Object, len(obj))
+	for k, v := range obj {
+		cp[k] = v
+	}
+	return cp
+}
+
+func unflatten(el Element, path string, made map[string]bool) Element {
+	obj, ok := el.(Object)
+	if !ok || !made[path] {
+		return el
+	}
+	for k, v := range obj {
+		sub := k
+		if path != "" {
+			sub = path + "." + k
+		}
+		obj[k] = unflatten(v, sub, made)
+	}
+	if len(obj) == 0 {
+		return obj
+	}
+	arr := make(Array, len(obj))
+	for k, v := range obj {
+		i, err := strconv.Atoi(k)
+		if err != nil || i < 0 || i >= len(arr) || strconv.Itoa(i) != k {
+			return obj
+		}
+		arr[i] = v
+	}
+	return arr
+}
@@ -0,0 +1,126 @@
+package saj
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestFlatten(t *testing.T) {
+	data := []struct {
+		Input string
+		Want  map[string]any
+	}{
+		{
+			Input: `{"a": {"b": 1}}`,
+			Want:  map[string]any{"a.b": float64(1)},
+		},
+		{
+			Input: `{"a": [1, 2]}`,
+			Want:  map[string]any{"a.0": float64(1), "a.1": float64(2)},
+		},
+		{
+			Input: `[{"name": "foo", "tags": []}, {"name": "bar", "meta": {}}]`,
+			Want: map[string]any{
+				"0.name": "foo",
+				"0.tags": []any{},
+				"1.name": "bar",
+				"1.meta": map[string]any{},
+			},
+		},
+		{
+			Input: `{}`,
+			Want:  map[string]any{},
+		},
+	}
+	for _, d := range data {
+		e, err := New(strings.NewReader(d.Input)).Read()
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %s", d.Input, err)
+		}
+		list, err := Flatten(e)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", d.Input, err)
+			continue
+		}
+		got := make(map[string]any)
+		for k, v := range list {
+			got[k] = ToGo(v)
+		}
+		if !reflect.DeepEqual(got, d.Want) {
+			t.Errorf("%s: want %v, got %v", d.Input, d.Want, got)
+			continue
+		}
+		back, err := Unflatten(list)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", d.Input, err)
+			continue
+		}
+		if !reflect.DeepEqual(ToGo(back), ToGo(e)) {
+			t.Errorf("%s: unflatten mismatched: got %v", d.Input, ToGo(back))
+		}
+	}
+}
+
+func TestFlatten_Error(t *testing.T) {
+	if _, err := Flatten(String("foo")); err == nil {
+		t.Errorf("scalar root should not be flattened")
+	}
+	if _, err := Flatten(Object{"a.b": Null()}); err == nil {
+		t.Errorf("key with dot should not be flattened")
+	}
+	list := map[string]Element{
+		"a":   String("foo"),
+		"a.b": String("bar"),
+	}
+	if _, err := Unflatten(list); err == nil {
+		t.Errorf("conflicting keys should not be unflattened")
+	}
+}
+
+func TestUnflatten_Input(t *testing.T) {
+	for i := 0; i < 10; i++ {
+		var (
+			leaf = Object{}
+			list = map[string]Element{
+				"a":   leaf,
+				"a.b": IntFrom(1),
+				"c.0": IntFrom(2),
+			}
+		)
+		got, err := Unflatten(list)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		want := Object{
+			"a": Object{"b": IntFrom(1)},
+			"c": Array{IntFrom(2)},
+		}
+		if !Equal(want, got) {
+			t.Errorf("want %v, got %v", want, got)
+		}
+		if len(leaf) != 0 {
+			t.Fatalf("input modified: %v", leaf)
+		}
+	}
+}
+
+func TestFlatten_MultiObject(t *testing.T) {
+	e, err := NewWithOptions(strings.NewReader(`{"a": 1, "b": {"c": true}, "a": 2}`), WithMultiObjects()).Read()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	got, err := Flatten(e)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	want := map[string]any{"a.0": 1.0, "a.1": 2.0, "b.0.c.0": true}
+	if len(got) != len(want) {
+		t.Fatalf("want %d values, got %d: %v", len(want), len(got), got)
+	}
+	for k, v := range want {
+		if x := Value(got[k]); !reflect.DeepEqual(x, v) {
+			t.Errorf("%s: want %v, got %v", k, v, x)
+		}
+	}
+}
@@ -0,0 +1,139 @@
+package saj
+
+import (
+	"errors"
+	"io"
+)
+
+func Format(w io.Writer, r io.Reader, indent string) error {
+	var (
+		rs = NewWithOptions(r, WithPreserveRaw())
+		f  = formatter{
+			e:      NewEncoder(w),
+			indent: indent,
+		}
+	)
+	for {
+		if _, err := rs.Peek(); errors.Is(err, io.EOF) {
+			break
+		}
+		if err := rs.Emit(&f); err != nil {
+			return err
+		}
+		f.e.w.WriteByte(nl)
+	}
+	return f.e.w.Flush()
+}
+
+type formatter struct {
+	e      *Encoder
+	indent string
+	counts []int
+	key    bool
+}
+
+func (f *formatter) StartObject() error {
+	f.value()
+	f.e.w.WriteByte(lcurly)
+	f.counts = append(f.counts, 0)
+	return nil
+}
+
+func (f *formatter) EndObject() error {
+	f.close(rcurly)
+	return nil
+}
+
+func (f *formatter) StartArray() error {
+	f.value()
+	f.e.w.WriteByte(lsquare)
+	f.counts = append(f.counts, 0)
+	return nil
+}
+
+func (f *formatter) EndArray() error {
+	f.close(rsquare)
+	return nil
+}
+
+func (f *formatter) Key(key string) error {
+	f.member()
+	f.e.encodeString(key)
+	f.e.w.WriteByte(colon)
+	f.e.w.WriteByte(space)
+	f.key = true
+	return nil
+}
+
+func (f *formatter) Scalar(el Element) error {
+	f.value()
+	return f.e.encode(el)
+}
+
+func (f *formatter) value() {
+	if f.key {
+		f.key = false
+		return
+	}
+	if len(f.counts) > 0 {
+		f.member()
+	}
+}
+
+func (f *formatter) member() {
+	n := len(f.counts)
+	if f.counts[n-1] > 0 {
+		f.e.w.WriteByte(comma)
+	}
+	f.counts[n-1]++
+	f.newline(n)
+}
+
+func (f *formatter) close(c byte) {
+	n := len(f.counts)
+	if f.counts[n-1] > 0 {
+		f.newline(n - 1)
+	}
+	f.counts = f.counts[:n-1]
+	f.e.w.WriteByte(c)
+}
+
+func (f *formatter) newline(depth int) {
+	f.e.w.WriteByte(nl)
+	for i := 0; i < depth; i++ {
+		f.e.w.WriteString(f.indent)
+	}
+}
+
+// Minify copies the values read from r to w without the whitespace (and
+// comments) between their tokens. Scalars are written exactly as they appear
+// in the input. Successive top-level values are separated by a newline.
+func Minify(w io.Writer, r io.Reader) error {
+	var (
+		rs    = New(r)
+		flush = func(event, Element) error {
+			_, err := rs.rawbuf.WriteTo(w)
+			return err
+		}
+	)
+	for i := 0; ; i++ {
+		if _, err := rs.Peek(); errors.Is(err, io.EOF) {
+			return nil
+		}
+		if i > 0 {
+			if _, err := io.WriteString(w, "\n"); err != nil {
+				return err
+			}
+		}
+		rs.capturing = true
+		rs.rawbuf.Reset()
+		err := rs.scan(&visitor{fn: flush})
+		rs.capturing = false
+		if err != nil {
+			return err
+		}
+		if err := flush(0, nil); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package saj
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestFormat(t *testing.T) {
+	data := []struct {
+		Input  string
+		Indent string
+		Want   string
+	}{
+		{
+			Input:  `{"name":"foo","tags":["a",  "b"],"empty":{},"list":[],"n":1.50e3,"ok":true,"nil":null}`,
+			Indent: "  ",
+			Want: `{
+  "name": "foo",
+  "tags": [
+    "a",
+    "b"
+  ],
+  "empty": {},
+  "list": [],
+  "n": 1.50e3,
+  "ok": true,
+  "nil": null
+}
+`,
+		},
+		{
+			Input:  `[[1,[2]],{"a":{"b":"xA\n"}}]`,
+			Indent: "\t",
+			Want:   "[\n\t[\n\t\t1,\n\t\t[\n\t\t\t2\n\t\t]\n\t],\n\t{\n\t\t\"a\": {\n\t\t\t\"b\": \"xA\\n\"\n\t\t}\n\t}\n]\n",
+		},
+		{
+			Input:  ` "foo"  42 [] `,
+			Indent: "  ",
+			Want:   "\"foo\"\n42\n[]\n",
+		},
+		{
+			Input: ``,
+			Want:  ``,
+		},
+	}
+	for _, d := range data {
+		var buf bytes.Buffer
+		if err := Format(&buf, strings.NewReader(d.Input), d.Indent); err != nil {
+			t.Errorf("%s: unexpected error: %s", d.Input, err)
+			continue
+		}
+		if got := buf.String(); got != d.Want {
+			t.Errorf("%s: want %q, got %q", d.Input, d.Want, got)
+		}
+	}
+	for _, str := range []string{`{"a": }`, `[1, 2`, `{"a" 1}`, `1 }`} {
+		if err := Format(&bytes.Buffer{}, strings.NewReader(str), "  "); err == nil {
+			t.Errorf("%s: invalid json formatted without error", str)
+		}
+	}
+}
+
+func TestMinify(t *testing.T) {
+	data := []struct {
+		Input string
+		Want  string
+	}{
+		{
+			Input: "{\n  \"name\" : \"f\\u006fo bar\",\n  \"n\": 1.50E+3 ,\"list\" : [ 1 , true , null , [ ] , { } ]\n}\n",
+			Want:  `{"name":"f\u006fo bar","n":1.50E+3,"list":[1,true,null,[],{}]}`,
+		},
+		{
+			Input: ` "a b"  -0.5 [ "x" ] `,
+			Want:  "\"a b\"\n-0.5\n[\"x\"]",
+		},
+		{
+			Input: "  ",
+			Want:  "",
+		},
+	}
+	for _, d := range data {
+		for name, rs := range readers(d.Input) {
+			var buf bytes.Buffer
+			if err := Minify(&buf, rs); err != nil {
+				t.Errorf("%q(%s): unexpected error: %s", d.Input, name, err)
+				continue
+			}
+			if got := buf.String(); got != d.Want {
+				t.Errorf("%q(%s): want %q, got %q", d.Input, name, d.Want, got)
+			}
+		}
+	}
+	for _, str := range []string{`{"a": }`, `[1, 2`, `{"a" 1}`, `1 }`} {
+		if err := Minify(&bytes.Buffer{}, strings.NewReader(str)); err == nil {
+			t.Errorf("%s: invalid json minified without error", str)
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package saj
+
+type Handler interface {
+	StartObject() error
+	EndObject() error
+	StartArray() error
+	EndArray() error
+	Key(key string) error
+	Scalar(el Element) error
+}
+
+// SpanHandler is implemented by handlers that want the byte offsets of the
+// tokens they receive. When the reader records token spans, Span is called
+// with the position of the token right before the callback reporting it.
+type SpanHandler interface {
+	Handler
+	Span(span Span)
+}
+
+// Emit reads the next value and reports its tokens to h as they are found
+// instead of building the Element tree.
+func (r *Reader) Emit(h Handler) error {
+	r.count = 0
+	sh, _ := h.(SpanHandler)
+	if !r.tokenSpans {
+		sh = nil
+	}
+	return r.scan(&visitor{
+		text: true,
+		fn: func(ev event, el Element) error {
+			if sh != nil {
+				sh.Span(r.token)
+			}
+			switch ev {
+			case evObjectStart:
+				return h.StartObject()
+			case evObjectEnd:
+				return h.EndObject()
+			case evArrayStart:
+				return h.StartArray()
+			case evArrayEnd:
+				return h.EndArray()
+			case evKey:
+				key, _ := AsString(el)
+				return h.Key(key)
+			default:
+				return h.Scalar(el)
+			}
+		},
+	})
+}
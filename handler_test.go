@@ -0,0 +1,81 @@
+package saj
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type recorder struct {
+	events []string
+	spans  []Span
+}
+
+func (r *recorder) StartObject() error {
+	r.events = append(r.events, "{")
+	return nil
+}
+
+func (r *recorder) EndObject() error {
+	r.events = append(r.events, "}")
+	return nil
+}
+
+func (r *recorder) StartArray() error {
+	r.events = append(r.events, "[")
+	return nil
+}
+
+func (r *recorder) EndArray() error {
+	r.events = append(r.events, "]")
+	return nil
+}
+
+func (r *recorder) Key(key string) error {
+	r.events = append(r.events, "key:"+key)
+	return nil
+}
+
+func (r *recorder) Scalar(el Element) error {
+	r.events = append(r.events, fmt.Sprintf("%s:%v", el.Type(), Value(el)))
+	return nil
+}
+
+func (r *recorder) Span(span Span) {
+	r.spans = append(r.spans, span)
+}
+
+func TestReader_Emit(t *testing.T) {
+	const input = `{"a": [1, "x"], "b" : {}, "c": null}`
+
+	var rec recorder
+	if err := New(strings.NewReader(input)).Emit(&rec); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	want := []string{"{", "key:a", "[", "number:1", "string:x", "]", "key:b", "{", "}", "key:c", "null:<nil>", "}"}
+	if !reflect.DeepEqual(rec.events, want) {
+		t.Errorf("want %v, got %v", want, rec.events)
+	}
+	if len(rec.spans) != 0 {
+		t.Errorf("spans reported without option: %v", rec.spans)
+	}
+}
+
+func TestReader_EmitSpans(t *testing.T) {
+	const input = ` {"a": [12, "x\n"], "b" : {}, "c": true}`
+
+	var rec recorder
+	if err := NewWithOptions(strings.NewReader(input), WithTokenSpans()).Emit(&rec); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(rec.spans) != len(rec.events) {
+		t.Fatalf("want %d spans, got %d", len(rec.events), len(rec.spans))
+	}
+	want := []string{`{`, `"a"`, `[`, `12`, `"x\n"`, `]`, `"b"`, `{`, `}`, `"c"`, `true`, `}`}
+	for i, s := range rec.spans {
+		if got := input[s.Start:s.End]; got != want[i] {
+			t.Errorf("%s: want %s, got %s", rec.events[i], want[i], got)
+		}
+	}
+}
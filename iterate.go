@@ -0,0 +1,274 @@
+package saj
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+type frame struct {
+	obj   Object
+	multi MultiObject
+	arr   Array
+	key   string
+	keep  bool
+	count int
+	start int64
+
+	member Trivia
+	notes  []string
+	keys   []string
+}
+
+func (f *frame) value() Element {
+	switch {
+	case f.multi != nil:
+		return f.multi
+	case f.obj != nil:
+		return f.obj
+	default:
+		return f.arr
+	}
+}
+
+func (f *frame) has(key string) bool {
+	if f.multi != nil {
+		_, ok := f.multi[key]
+		return ok
+	}
+	_, ok := f.obj[key]
+	return ok
+}
+
+func (f *frame) object() bool {
+	return f.obj != nil || f.multi != nil
+}
+
+// iterate builds the same tree as read but keeps the open containers on an
+// explicit stack instead of recursing into object and array.
+func (r *Reader) iterate() (Element, error) {
+	var (
+		stack []*frame
+		base  = len(r.path)
+	)
+	defer func() {
+		for range stack {
+			r.leave()
+		}
+		if len(r.path) > base {
+			r.path = r.path[:base]
+		}
+	}()
+	for {
+		r.skipBlank()
+		c, err := r.next()
+		if err != nil {
+			return nil, err
+		}
+		var el Element
+		if isObject(c) || isArray(c) {
+			f, err := r.open(c)
+			if err != nil {
+				return nil, err
+			}
+			stack = append(stack, f)
+			done, err := r.first(f)
+			if err != nil {
+				return nil, err
+			}
+			if !done {
+				continue
+			}
+			el = r.close(f)
+			stack = stack[:len(stack)-1]
+		} else {
+			r.reset()
+			if el, err = r.read(); err != nil {
+				return nil, err
+			}
+		}
+		for len(stack) > 0 {
+			f := stack[len(stack)-1]
+			r.trail(f)
+			r.pop()
+			if err := r.attach(f, el); err != nil {
+				return nil, err
+			}
+			done, err := r.following(f)
+			if err != nil {
+				return nil, err
+			}
+			if !done {
+				break
+			}
+			el = r.close(f)
+			stack = stack[:len(stack)-1]
+		}
+		if len(stack) == 0 {
+			return el, nil
+		}
+	}
+}
+
+func (r *Reader) open(c rune) (*frame, error) {
+	if err := r.incr(); err != nil {
+		return nil, err
+	}
+	f := frame{
+		start: r.offset - int64(r.size),
+	}
+	if err := r.enter(); err != nil {
+		r.leave()
+		return nil, err
+	}
+	if isObject(c) && r.multi {
+		f.multi = make(MultiObject)
+	} else if isObject(c) {
+		f.obj = r.newObject()
+	} else {
+		f.arr = r.newArray()
+	}
+	return &f, nil
+}
+
+func (r *Reader) close(f *frame) Element {
+	r.layout(f.keys)
+	r.leave()
+	if r.spans != nil {
+		r.spans[joinPointer(r.path)] = Span{
+			Start: f.start,
+			End:   r.offset,
+		}
+	}
+	r.skipBlank()
+	return f.value()
+}
+
+func (r *Reader) first(f *frame) (bool, error) {
+	if f.object() {
+		return r.entry(f)
+	}
+	r.skipBlank()
+	if c, _ := r.next(); c == rsquare {
+		return true, nil
+	}
+	r.reset()
+	return false, r.slot(f)
+}
+
+func (r *Reader) slot(f *frame) error {
+	if err := r.arrayLimit(len(f.arr) + 1); err != nil {
+		return err
+	}
+	f.member = Trivia{
+		Before: r.take(),
+	}
+	f.notes = r.takeNotes()
+	r.push(strconv.Itoa(len(f.arr)))
+	return nil
+}
+
+func (r *Reader) entry(f *frame) (bool, error) {
+	for {
+		key, err := r.key()
+		if err != nil {
+			if !errors.Is(err, errEmpty) {
+				return false, err
+			}
+			r.next()
+			return true, nil
+		}
+		f.count++
+		if err := r.keyLimit(f.count); err != nil {
+			return false, err
+		}
+		f.member = r.member
+		f.notes = r.takeNotes()
+		f.key, f.keep = key, true
+		if r.keyFilter != nil {
+			f.key, f.keep = r.keyFilter(key)
+		}
+		if f.keep {
+			r.push(f.key)
+			return false, nil
+		}
+		if err := r.skip(); err != nil {
+			return false, err
+		}
+		r.take()
+		if done, err := r.separated(f); done || err != nil {
+			return done, err
+		}
+	}
+}
+
+func (r *Reader) attach(f *frame, el Element) error {
+	if !f.object() {
+		f.arr = append(f.arr, el)
+		return nil
+	}
+	if !f.keep {
+		return nil
+	}
+	if r.trivia != nil && !f.has(f.key) {
+		f.keys = append(f.keys, f.key)
+	}
+	if f.multi != nil {
+		f.multi.Add(f.key, el)
+		return nil
+	}
+	return r.set(f.obj, f.key, el)
+}
+
+func (r *Reader) following(f *frame) (bool, error) {
+	if done, err := r.separated(f); done || err != nil {
+		return done, err
+	}
+	if f.object() {
+		return r.entry(f)
+	}
+	return false, r.slot(f)
+}
+
+// separated reads the comma after a member of f, reporting whether the end
+// of f was found instead.
+func (r *Reader) separated(f *frame) (bool, error) {
+	end, what := rsquare, "array"
+	if f.object() {
+		end, what = rcurly, "object"
+	}
+	c, err := r.next()
+	if err != nil {
+		return false, err
+	}
+	if c == end {
+		return true, nil
+	}
+	if c != comma {
+		return false, fmt.Errorf("%s: unexpected character %c", what, c)
+	}
+	r.skipBlank()
+	if c, err := r.next(); err != nil {
+		return false, err
+	} else if c == end && r.trailing {
+		return true, nil
+	} else if c == end {
+		return false, fmt.Errorf("%s: unexpected ',' before '%c'", what, end)
+	}
+	r.reset()
+	return false, nil
+}
+
+// trail records the trivia of the member of f that was just read, as
+// property and item do.
+func (r *Reader) trail(f *frame) {
+	if r.trivia == nil {
+		return
+	}
+	m := f.member
+	m.After = r.take()
+	r.setTrivia(func(t *Trivia) {
+		t.Before, t.Key, t.Colon, t.After = m.Before, m.Key, m.Colon, m.After
+		t.Comments = f.notes
+	})
+}
@@ -0,0 +1,192 @@
+package saj
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestReader_ReadIterative(t *testing.T) {
+	data := []string{
+		`"foobar"`,
+		`42`,
+		`[]`,
+		`{}`,
+		`[[], {}, [[]], {"a": {}}]`,
+		`{"name": "foo", "tags": ["a", "b"], "nested": {"list": [1, {"x": null}], "ok": true}}`,
+		` [ 1 , [ 2 , 3 ] , { "four" : 4 } ] `,
+	}
+	files, _ := filepath.Glob("data/*.json")
+	for _, file := range files {
+		buf, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %s", file, err)
+		}
+		data = append(data, string(buf))
+	}
+	for _, d := range data {
+		want, err := New(strings.NewReader(d)).Read()
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %s", d, err)
+		}
+		for name, rs := range readers(d) {
+			got, err := New(rs).ReadIterative()
+			if err != nil {
+				t.Errorf("%s(%s): unexpected error: %s", d, name, err)
+				continue
+			}
+			if !reflect.DeepEqual(want, got) {
+				t.Errorf("%s(%s): want %v, got %v", d, name, want, got)
+			}
+		}
+	}
+}
+
+func TestReader_ReadIterativeError(t *testing.T) {
+	data := []string{
+		`["unclosed", "array`,
+		`["trailing", "comma", ]`,
+		`{"name" "foobar"}`,
+		`{"name": "foobar",}`,
+		`{"name": }`,
+		`{"unclosed": "object"`,
+		`{true: false}`,
+		`[1 2]`,
+		`[+5]`,
+		`[1.]`,
+		`]`,
+	}
+	for _, d := range data {
+		if e, err := New(strings.NewReader(d)).ReadIterative(); err == nil {
+			t.Errorf("%s: invalid json parsed properly as %v", d, e)
+		}
+	}
+}
+
+func TestReader_ReadIterativeDeep(t *testing.T) {
+	const depth = 100000
+	input := strings.Repeat(`{"a":[`, depth) + strings.Repeat(`]}`, depth)
+	e, err := New(strings.NewReader(input)).ReadIterative()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	for i := 0; i < depth; i++ {
+		obj, ok := AsObject(e)
+		if !ok {
+			t.Fatalf("%d: expected object, got %T", i, e)
+		}
+		arr, ok := AsArray(obj["a"])
+		if !ok {
+			t.Fatalf("%d: expected array, got %T", i, obj["a"])
+		}
+		if i < depth-1 {
+			e = arr[0]
+		}
+	}
+
+	r := NewWithOptions(strings.NewReader(input), WithMaxDepth(64))
+	if _, err := r.ReadIterative(); err == nil {
+		t.Fatalf("maximum depth not enforced")
+	}
+	r.Reset(strings.NewReader(`[[1]]`))
+	if _, err := r.ReadIterative(); err != nil {
+		t.Errorf("unexpected error after reset: %s", err)
+	}
+}
+
+func TestReader_ReadIterativeOptions(t *testing.T) {
+	const input = `{"a": [1, {"b": "x"}], "a": 2, "c": [],}`
+	opts := []Option{
+		WithTrailingCommas(),
+		WithSpans(),
+		WithKeyFilter(func(key string) (string, bool) {
+			return strings.ToUpper(key), key != "c"
+		}),
+	}
+	r1 := NewWithOptions(strings.NewReader(input), opts...)
+	want, err := r1.Read()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	r2 := NewWithOptions(strings.NewReader(input), opts...)
+	got, err := r2.ReadIterative()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if !reflect.DeepEqual(want, got) {
+		t.Errorf("want %v, got %v", want, got)
+	}
+	if !reflect.DeepEqual(r1.Spans(), r2.Spans()) {
+		t.Errorf("spans mismatched: want %v, got %v", r1.Spans(), r2.Spans())
+	}
+}
+
+func TestReader_ReadIterativeMaxKeys(t *testing.T) {
+	r := NewWithOptions(strings.NewReader(`[{"a": 1, "b": 2, "c": 3}]`), WithMaxObjectKeys(2))
+	if e, err := r.ReadIterative(); err == nil {
+		t.Errorf("maximum number of keys not enforced: %v", e)
+	}
+}
+
+func TestReader_ReadIterativeMaxArrayLength(t *testing.T) {
+	for _, str := range []string{`[1, 2, 3]`, `{"a": [1, [2, 3, 4]]}`} {
+		r := NewWithOptions(strings.NewReader(str), WithMaxArrayLength(2))
+		if e, err := r.ReadIterative(); err == nil {
+			t.Errorf("%s: maximum array length not enforced: %v", str, e)
+		}
+	}
+	r := NewWithOptions(strings.NewReader(`[[1, 2], [3, 4]]`), WithMaxArrayLength(2))
+	if _, err := r.ReadIterative(); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+}
+
+func TestReader_ReadIterativeMultiObjects(t *testing.T) {
+	const input = `[{"a": 1, "b": {"c": true, "c": false}, "a": 2}]`
+
+	want, err := NewWithOptions(strings.NewReader(input), WithMultiObjects()).Read()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	got, err := NewWithOptions(strings.NewReader(input), WithMultiObjects()).ReadIterative()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if _, ok := got.(Array)[0].(MultiObject); !ok || !Equal(got, want) {
+		t.Errorf("want %v, got %v", want, got)
+	}
+}
+
+func TestReader_ReadIterativeTrivia(t *testing.T) {
+	const input = `
+{
+  "b": [1,  2, { } ],
+  "a" : "x", // note
+  "c": {"d": true  }
+}
+`
+	opts := []Option{WithPreserveFormat(), WithPreserveComments(), WithPreserveRaw()}
+	r1 := NewWithOptions(strings.NewReader(input), opts...)
+	if _, err := r1.Read(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	r2 := NewWithOptions(strings.NewReader(input), opts...)
+	e, err := r2.ReadIterative()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if !reflect.DeepEqual(r1.Trivia(), r2.Trivia()) {
+		t.Errorf("trivia mismatched: want %v, got %v", r1.Trivia(), r2.Trivia())
+	}
+	var str strings.Builder
+	enc := NewEncoder(&str)
+	enc.SetTrivia(r2.Trivia())
+	if err := enc.Encode(e); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if str.String() != input {
+		t.Errorf("layout not preserved: want %q, got %q", input, str.String())
+	}
+}
@@ -0,0 +1,78 @@
+package saj
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+type LineError struct {
+	Line int
+	Err  error
+}
+
+func (e LineError) Error() string {
+	return fmt.Sprintf("line %d: %s", e.Line, e.Err)
+}
+
+func (e LineError) Unwrap() error {
+	return e.Err
+}
+
+type LineReader struct {
+	rs   *bufio.Reader
+	line int
+	err  error
+	skip bool
+}
+
+func NewLineReader(r io.Reader) *LineReader {
+	return &LineReader{
+		rs: bufio.NewReader(r),
+	}
+}
+
+// SetContinueOnError makes Next return a LineError for a malformed line and
+// resume at the following line on the next call instead of stopping.
+func (r *LineReader) SetContinueOnError(cont bool) {
+	r.skip = cont
+}
+
+func (r *LineReader) Next() (Element, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+	for {
+		line, err := r.rs.ReadString(nl)
+		if err != nil && (len(line) == 0 || !errors.Is(err, io.EOF)) {
+			r.err = err
+			return nil, err
+		}
+		r.line++
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		el, err := r.parse(line)
+		if err != nil && !r.skip {
+			r.err = err
+		}
+		return el, err
+	}
+}
+
+func (r *LineReader) parse(line string) (Element, error) {
+	rs := New(strings.NewReader(line))
+	el, err := rs.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			err = fmt.Errorf("incomplete value")
+		}
+		return nil, LineError{Line: r.line, Err: err}
+	}
+	if c, err := rs.next(); err == nil {
+		return nil, LineError{Line: r.line, Err: fmt.Errorf("unexpected character %c after value", c)}
+	}
+	return el, nil
+}
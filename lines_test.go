@@ -0,0 +1,92 @@
+package saj
+
+import (
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLineReader(t *testing.T) {
+	const input = `{"name": "foo", "age": 10}
+
+  [1, 2, 3]
+"foobar"
+true`
+
+	var (
+		rs    = NewLineReader(strings.NewReader(input))
+		types = []ElementType{TypeObject, TypeArray, TypeString, TypeBool}
+	)
+	for i := 0; ; i++ {
+		e, err := rs.Next()
+		if errors.Is(err, io.EOF) {
+			if i != len(types) {
+				t.Errorf("expected %d values, got %d", len(types), i)
+			}
+			break
+		}
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if i >= len(types) {
+			t.Fatalf("too many values read")
+		}
+		if e.Type() != types[i] {
+			t.Errorf("%d: unexpected element type", i)
+		}
+	}
+}
+
+func TestLineReader_Error(t *testing.T) {
+	data := []string{
+		"{\"name\":\n\"foobar\"}",
+		"[1, 2,\n3]",
+		"1 2",
+		"{\"name\": \"foobar\"} []",
+	}
+	for _, d := range data {
+		rs := NewLineReader(strings.NewReader(d))
+		if e, err := rs.Next(); err == nil || errors.Is(err, io.EOF) {
+			t.Errorf("%q: invalid ndjson parsed properly as %v", d, e)
+		}
+	}
+}
+
+func TestLineReader_ContinueOnError(t *testing.T) {
+	const input = `{"name": "foo"}
+{"name": 
+[1, 2, 3]
+nope
+"foobar"`
+
+	rs := NewLineReader(strings.NewReader(input))
+	rs.SetContinueOnError(true)
+
+	var (
+		types []ElementType
+		lines []int
+	)
+	for {
+		e, err := rs.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			var le LineError
+			if !errors.As(err, &le) {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			lines = append(lines, le.Line)
+			continue
+		}
+		types = append(types, e.Type())
+	}
+	if want := []ElementType{TypeObject, TypeArray, TypeString}; !reflect.DeepEqual(types, want) {
+		t.Errorf("want %v, got %v", want, types)
+	}
+	if want := []int{2, 4}; !reflect.DeepEqual(lines, want) {
+		t.Errorf("want %v, got %v", want, lines)
+	}
+}
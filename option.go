@@ -0,0 +1,215 @@
+package saj
+
+import (
+	"io"
+)
+
+type Option func(*Reader)
+
+func WithMaxDepth(n int) Option {
+	return func(r *Reader) {
+		r.SetMaxDepth(n)
+	}
+}
+
+func WithMaxValueSize(n int) Option {
+	return func(r *Reader) {
+		r.SetMaxValueSize(n)
+	}
+}
+
+func WithComments() Option {
+	return func(r *Reader) {
+		r.SetComments(true)
+	}
+}
+
+func WithTrailingCommas() Option {
+	return func(r *Reader) {
+		r.SetTrailingCommas(true)
+	}
+}
+
+func WithCommaSeparatedValues() Option {
+	return func(r *Reader) {
+		r.SetCommaSeparatedValues(true)
+	}
+}
+
+func WithMultiObjects() Option {
+	return func(r *Reader) {
+		r.SetMultiObjects(true)
+	}
+}
+
+func WithDuplicateKeyFunc(fn func(key string, old, new Element) (Element, error)) Option {
+	return func(r *Reader) {
+		r.SetDuplicateKeyFunc(fn)
+	}
+}
+
+func WithMaxElements(n int) Option {
+	return func(r *Reader) {
+		r.SetMaxElements(n)
+	}
+}
+
+func WithInvalidUTF8Policy(policy UTF8Policy) Option {
+	return func(r *Reader) {
+		r.SetInvalidUTF8Policy(policy)
+	}
+}
+
+func WithMaxNumberLength(n int) Option {
+	return func(r *Reader) {
+		r.SetMaxNumberLength(n)
+	}
+}
+
+func WithSpans() Option {
+	return func(r *Reader) {
+		r.SetRecordSpans(true)
+	}
+}
+
+func WithSingleQuotes() Option {
+	return func(r *Reader) {
+		r.SetSingleQuotes(true)
+	}
+}
+
+func WithUnquotedKeys() Option {
+	return func(r *Reader) {
+		r.SetUnquotedKeys(true)
+	}
+}
+
+func NewJSON5(r io.Reader) *Reader {
+	return NewWithOptions(r, WithComments(), WithTrailingCommas(), WithSingleQuotes(), WithUnquotedKeys())
+}
+
+func WithKeyFilter(fn func(key string) (string, bool)) Option {
+	return func(r *Reader) {
+		r.SetKeyFilter(fn)
+	}
+}
+
+func WithValueFilter(fn func(path string, el Element) (Element, error)) Option {
+	return func(r *Reader) {
+		r.SetValueFilter(fn)
+	}
+}
+
+func WithEmptyAsNull() Option {
+	return func(r *Reader) {
+		r.SetEmptyAsNull(true)
+	}
+}
+
+func WithTopLevelMode(mode TopLevelMode) Option {
+	return func(r *Reader) {
+		r.SetTopLevelMode(mode)
+	}
+}
+
+func WithStringSink(fn func(key string) io.Writer) Option {
+	return func(r *Reader) {
+		r.SetStringSink(fn)
+	}
+}
+
+func WithPreserveRaw() Option {
+	return func(r *Reader) {
+		r.SetPreserveRaw(true)
+	}
+}
+
+func WithPreserveFormat() Option {
+	return func(r *Reader) {
+		r.SetPreserveFormat(true)
+	}
+}
+
+func WithStrictWhitespace() Option {
+	return func(r *Reader) {
+		r.SetStrictWhitespace(true)
+	}
+}
+
+func WithZeroCopyKeys() Option {
+	return func(r *Reader) {
+		r.SetZeroCopyKeys(true)
+	}
+}
+
+func WithZeroCopyStrings() Option {
+	return func(r *Reader) {
+		r.SetZeroCopyStrings(true)
+	}
+}
+
+func WithProgressFunc(everyBytes int, fn func(bytesRead int64)) Option {
+	return func(r *Reader) {
+		r.SetProgressFunc(everyBytes, fn)
+	}
+}
+
+func WithMaxObjectKeys(n int) Option {
+	return func(r *Reader) {
+		r.SetMaxObjectKeys(n)
+	}
+}
+
+func WithMaxArrayLength(n int) Option {
+	return func(r *Reader) {
+		r.SetMaxArrayLength(n)
+	}
+}
+
+func WithResumable() Option {
+	return func(r *Reader) {
+		r.SetResumable(true)
+	}
+}
+
+func WithKeyNormalizer(fn func(string) string) Option {
+	return func(r *Reader) {
+		r.SetKeyNormalizer(fn)
+	}
+}
+
+func WithIntegers() Option {
+	return func(r *Reader) {
+		r.SetIntegers(true)
+	}
+}
+
+func WithNumberParser(fn func(raw string) (Element, error)) Option {
+	return func(r *Reader) {
+		r.SetNumberParser(fn)
+	}
+}
+
+func WithTokenSpans() Option {
+	return func(r *Reader) {
+		r.SetTokenSpans(true)
+	}
+}
+
+func WithErrorRecovery() Option {
+	return func(r *Reader) {
+		r.SetErrorRecovery(true)
+	}
+}
+
+func WithPreserveComments() Option {
+	return func(r *Reader) {
+		r.SetPreserveComments(true)
+	}
+}
+
+func WithDisallowUnknownFields() Option {
+	return func(r *Reader) {
+		r.SetDisallowUnknownFields(true)
+	}
+}
@@ -0,0 +1,197 @@
+package saj
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestReader_Options(t *testing.T) {
+	data := []struct {
+		Input   string
+		Options []Option
+		Valid   bool
+	}{
+		{
+			Input:   `[[[1]]]`,
+			Options: []Option{WithMaxDepth(3)},
+			Valid:   true,
+		},
+		{
+			Input:   `[[[{"one": 1}]]]`,
+			Options: []Option{WithMaxDepth(3)},
+		},
+		{
+			Input:   `[1, [2, 3], {"four": 4}]`,
+			Options: []Option{WithMaxElements(7)},
+			Valid:   true,
+		},
+		{
+			Input:   `[1, [2, 3], {"four": 4}, 5]`,
+			Options: []Option{WithMaxElements(7)},
+		},
+		{
+			Input:   `{"a": 1, "b": {"c": 2, "d": 3}, "e": 4}`,
+			Options: []Option{WithMaxObjectKeys(3)},
+			Valid:   true,
+		},
+		{
+			Input:   `{"a": 1, "b": 2, "c": 3, "d": 4}`,
+			Options: []Option{WithMaxObjectKeys(3)},
+		},
+		{
+			Input:   `[{"a": 1, "a": 2, "a": 3, "a": 4}]`,
+			Options: []Option{WithMaxObjectKeys(3)},
+		},
+		{
+			Input:   `{"a": [1, 2, 3], "b": [[4, 5, 6]]}`,
+			Options: []Option{WithMaxArrayLength(3)},
+			Valid:   true,
+		},
+		{
+			Input:   `[1, 2, 3, 4]`,
+			Options: []Option{WithMaxArrayLength(3)},
+		},
+		{
+			Input:   `{"a": [[1, 2, 3, 4]]}`,
+			Options: []Option{WithMaxArrayLength(3)},
+		},
+		{
+			Input:   `  {"name": "foo"}`,
+			Options: []Option{WithTopLevelMode(RequireContainer)},
+			Valid:   true,
+		},
+		{
+			Input:   `[]`,
+			Options: []Option{WithTopLevelMode(RequireContainer)},
+			Valid:   true,
+		},
+		{
+			Input:   `"foobar"`,
+			Options: []Option{WithTopLevelMode(RequireContainer)},
+		},
+		{
+			Input:   `  42`,
+			Options: []Option{WithTopLevelMode(RequireContainer)},
+		},
+		{
+			Input:   `null`,
+			Options: []Option{WithTopLevelMode(RequireContainer)},
+		},
+		{
+			Input:   `true`,
+			Options: []Option{WithTopLevelMode(AllowAnyValue)},
+			Valid:   true,
+		},
+		{
+			Input: `// leading comment
+{
+	/* block comment */
+	"name": "foobar", // trailing comment
+	"age": 0/* after zero */,
+	"enabled": true// after ident
+}`,
+			Options: []Option{WithComments()},
+			Valid:   true,
+		},
+		{
+			Input: `{"name": /* comment */ "foobar"}`,
+		},
+		{
+			Input:   `{"name": "foobar" /* unterminated }`,
+			Options: []Option{WithComments()},
+		},
+		{
+			Input:   `[1 /2]`,
+			Options: []Option{WithComments()},
+		},
+		{
+			Input:   `[1, 2, 3, ]`,
+			Options: []Option{WithTrailingCommas()},
+			Valid:   true,
+		},
+		{
+			Input:   `{"name": "foobar",}`,
+			Options: []Option{WithTrailingCommas()},
+			Valid:   true,
+		},
+		{
+			Input:   `[,]`,
+			Options: []Option{WithTrailingCommas()},
+		},
+		{
+			Input:   `[1,,]`,
+			Options: []Option{WithTrailingCommas()},
+		},
+		{
+			Input:   `{"name": [1, 2],"age":0}`,
+			Options: []Option{WithStrictWhitespace()},
+			Valid:   true,
+		},
+		{
+			Input:   ` {"name": "foobar"}`,
+			Options: []Option{WithStrictWhitespace()},
+		},
+		{
+			Input:   "{\"name\": \"foobar\"}\n",
+			Options: []Option{WithStrictWhitespace()},
+		},
+		{
+			Input:   `42 `,
+			Options: []Option{WithStrictWhitespace()},
+		},
+	}
+	for _, d := range data {
+		e, err := NewWithOptions(strings.NewReader(d.Input), d.Options...).Read()
+		if d.Valid && err != nil {
+			t.Errorf("%s: unexpected error: %s", d.Input, err)
+		}
+		if !d.Valid && err == nil {
+			t.Errorf("%s: invalid json parsed properly as %v", d.Input, e)
+		}
+		if err != nil && e != nil {
+			t.Errorf("%s: element returned with error: %v", d.Input, e)
+		}
+	}
+}
+
+func TestNewJSON5(t *testing.T) {
+	const input = `// application settings
+{
+	name: 'foo "bar"',
+	$version: 2,
+	'quoted': "it\'s",
+	tags: ['a', 'b',],
+	/* nested */
+	nested_1: {enabled: true,},
+}`
+	e, err := NewJSON5(strings.NewReader(input)).Read()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	obj, ok := AsObject(e)
+	if !ok {
+		t.Fatalf("expected object, got %T", e)
+	}
+	for _, k := range []string{"name", "$version", "quoted", "tags", "nested_1"} {
+		if _, ok := obj[k]; !ok {
+			t.Errorf("%s: key not found", k)
+		}
+	}
+	if s, _ := AsString(obj["name"]); s != `foo "bar"` {
+		t.Errorf("unexpected single quoted string: %s", s)
+	}
+	if s, _ := AsString(obj["quoted"]); s != "it's" {
+		t.Errorf("unexpected escaped quote: %s", s)
+	}
+
+	data := []string{
+		`{'name': 'foo'}`,
+		`{name: "foo"}`,
+		`"it\'s"`,
+	}
+	for _, d := range data {
+		if e, err := New(strings.NewReader(d)).Read(); err == nil {
+			t.Errorf("%s: invalid json parsed properly as %v", d, e)
+		}
+	}
+}
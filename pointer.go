@@ -0,0 +1,288 @@
+package saj
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var (
+	pointerEscaper   = strings.NewReplacer("~", "~0", "/", "~1")
+	pointerUnescaper = strings.NewReplacer("~1", "/", "~0", "~")
+)
+
+func joinPointer(path []string) string {
+	var str strings.Builder
+	for _, p := range path {
+		str.WriteByte(slash)
+		str.WriteString(pointerEscaper.Replace(p))
+	}
+	return str.String()
+}
+
+func splitPointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return nil, nil
+	}
+	if pointer[0] != slash {
+		return nil, fmt.Errorf("pointer %s: must start with '/'", pointer)
+	}
+	path := strings.Split(pointer[1:], "/")
+	for i, p := range path {
+		for j := 0; j < len(p); j++ {
+			if p[j] == '~' && (j+1 == len(p) || (p[j+1] != '0' && p[j+1] != '1')) {
+				return nil, fmt.Errorf("pointer %s: invalid escape sequence", pointer)
+			}
+		}
+		path[i] = pointerUnescaper.Replace(p)
+	}
+	return path, nil
+}
+
+// Get returns the value found at pointer in root. The key of a MultiObject
+// designates the array of its values, so that /a/1 is the second value of a.
+func Get(root Element, pointer string) (Element, error) {
+	path, err := splitPointer(pointer)
+	if err != nil {
+		return nil, err
+	}
+	el := root
+	for i, p := range path {
+		switch e := el.(type) {
+		case Object:
+			v, ok := e[p]
+			if !ok {
+				return nil, fmt.Errorf("pointer %s: key not found", joinPointer(path[:i+1]))
+			}
+			el = v
+		case MultiObject:
+			vs, ok := e[p]
+			if !ok {
+				return nil, fmt.Errorf("pointer %s: key not found", joinPointer(path[:i+1]))
+			}
+			el = Array(vs)
+		case Array:
+			x, err := arrayIndex(e, p, path[:i+1])
+			if err != nil {
+				return nil, err
+			}
+			if x == len(e) {
+				return nil, fmt.Errorf("pointer %s: index out of range", joinPointer(path[:i+1]))
+			}
+			el = e[x]
+		default:
+			return nil, fmt.Errorf("pointer %s: can not traverse %s", joinPointer(path[:i+1]), typeOf(el))
+		}
+	}
+	return el, nil
+}
+
+func GetOr(root Element, pointer string, def Element) Element {
+	el, err := Get(root, pointer)
+	if err != nil {
+		return def
+	}
+	return el
+}
+
+func Set(root Element, pointer string, value Element) (Element, error) {
+	return setPointer(root, pointer, value, false)
+}
+
+// SetAll is like Set but creates the missing objects along the path instead
+// of returning an error.
+func SetAll(root Element, pointer string, value Element) (Element, error) {
+	return setPointer(root, pointer, value, true)
+}
+
+func setPointer(root Element, pointer string, value Element, create bool) (Element, error) {
+	path, err := splitPointer(pointer)
+	if err != nil {
+		return nil, err
+	}
+	return setPath(root, path, 0, value, create)
+}
+
+func setPath(el Element, path []string, i int, value Element, create bool) (Element, error) {
+	if i == len(path) {
+		return value, nil
+	}
+	var (
+		last = i == len(path)-1
+		key  = path[i]
+	)
+	switch e := el.(type) {
+	case Object:
+		child, ok := e[key]
+		if !ok && !last {
+			if !create {
+				return nil, fmt.Errorf("pointer %s: key not found", joinPointer(path[:i+1]))
+			}
+			child = Object{}
+		}
+		v, err := setPath(child, path, i+1, value, create)
+		if err != nil {
+			return nil, err
+		}
+		obj := make(Object, len(e)+1)
+		for k, v := range e {
+			obj[k] = v
+		}
+		obj[key] = v
+		return obj, nil
+	case MultiObject:
+		vs, ok := e[key]
+		if !ok && !last && !create {
+			return nil, fmt.Errorf("pointer %s: key not found", joinPointer(path[:i+1]))
+		}
+		multi := make(MultiObject, len(e)+1)
+		for k, vs := range e {
+			multi[k] = vs
+		}
+		if last {
+			multi[key] = []Element{value}
+			return multi, nil
+		}
+		v, err := setPath(Array(vs), path, i+1, value, create)
+		if err != nil {
+			return nil, err
+		}
+		multi[key] = v.(Array)
+		return multi, nil
+	case Array:
+		x, err := arrayIndex(e, key, path[:i+1])
+		if err != nil {
+			return nil, err
+		}
+		var child Element
+		if x < len(e) {
+			child = e[x]
+		} else if !last {
+			if !create {
+				return nil, fmt.Errorf("pointer %s: index out of range", joinPointer(path[:i+1]))
+			}
+			child = Object{}
+		}
+		v, err := setPath(child, path, i+1, value, create)
+		if err != nil {
+			return nil, err
+		}
+		arr := make(Array, len(e), len(e)+1)
+		copy(arr, e)
+		if x == len(e) {
+			arr = append(arr, v)
+		} else {
+			arr[x] = v
+		}
+		return arr, nil
+	default:
+		return nil, fmt.Errorf("pointer %s: can not traverse %s", joinPointer(path[:i+1]), typeOf(el))
+	}
+}
+
+func Remove(root Element, pointer string) (Element, error) {
+	path, err := splitPointer(pointer)
+	if err != nil {
+		return nil, err
+	}
+	if len(path) == 0 {
+		return nil, fmt.Errorf("pointer: root can not be removed")
+	}
+	return removePath(root, path, 0)
+}
+
+func removePath(el Element, path []string, i int) (Element, error) {
+	var (
+		last = i == len(path)-1
+		key  = path[i]
+	)
+	switch e := el.(type) {
+	case Object:
+		child, ok := e[key]
+		if !ok {
+			return nil, fmt.Errorf("pointer %s: key not found", joinPointer(path[:i+1]))
+		}
+		obj := make(Object, len(e))
+		for k, v := range e {
+			obj[k] = v
+		}
+		if last {
+			delete(obj, key)
+			return obj, nil
+		}
+		v, err := removePath(child, path, i+1)
+		if err != nil {
+			return nil, err
+		}
+		obj[key] = v
+		return obj, nil
+	case MultiObject:
+		vs, ok := e[key]
+		if !ok {
+			return nil, fmt.Errorf("pointer %s: key not found", joinPointer(path[:i+1]))
+		}
+		multi := make(MultiObject, len(e))
+		for k, vs := range e {
+			multi[k] = vs
+		}
+		if last {
+			delete(multi, key)
+			return multi, nil
+		}
+		v, err := removePath(Array(vs), path, i+1)
+		if err != nil {
+			return nil, err
+		}
+		multi[key] = v.(Array)
+		return multi, nil
+	case Array:
+		x, err := arrayIndex(e, key, path[:i+1])
+		if err != nil {
+			return nil, err
+		}
+		if x == len(e) {
+			return nil, fmt.Errorf("pointer %s: index out of range", joinPointer(path[:i+1]))
+		}
+		if last {
+			arr := make(Array, 0, len(e)-1)
+			arr = append(arr, e[:x]...)
+			return append(arr, e[x+1:]...), nil
+		}
+		v, err := removePath(e[x], path, i+1)
+		if err != nil {
+			return nil, err
+		}
+		arr := make(Array, len(e))
+		copy(arr, e)
+		arr[x] = v
+		return arr, nil
+	default:
+		return nil, fmt.Errorf("pointer %s: can not traverse %s", joinPointer(path[:i+1]), typeOf(el))
+	}
+}
+
+// arrayIndex returns the index designated by p in arr. The "-" token gives
+// len(arr), the position right after the last element.
+func arrayIndex(arr Array, p string, path []string) (int, error) {
+	if p == "-" {
+		return len(arr), nil
+	}
+	if p == "" || (len(p) > 1 && p[0] == '0') || p[0] == '+' || p[0] == '-' {
+		return 0, fmt.Errorf("pointer %s: invalid array index", joinPointer(path))
+	}
+	x, err := strconv.Atoi(p)
+	if err != nil {
+		return 0, fmt.Errorf("pointer %s: invalid array index", joinPointer(path))
+	}
+	if x >= len(arr) {
+		return 0, fmt.Errorf("pointer %s: index out of range", joinPointer(path))
+	}
+	return x, nil
+}
+
+func typeOf(el Element) string {
+	if el == nil {
+		return "missing value"
+	}
+	return el.Type().String()
+}
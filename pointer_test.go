@@ -0,0 +1,222 @@
+package saj
+
+import (
+	"strings"
+	"testing"
+)
+
+const pointerDoc = `{
+	"foo": ["bar", "baz"],
+	"": 0,
+	"a/b": 1,
+	"m~n": 8,
+	"nested": {"list": [{"id": 1}, {"id": 2}]}
+}`
+
+func TestGet(t *testing.T) {
+	root, err := New(strings.NewReader(pointerDoc)).Read()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	data := []struct {
+		Pointer string
+		Want    string
+	}{
+		{Pointer: "/foo", Want: `["bar", "baz"]`},
+		{Pointer: "/foo/0", Want: `"bar"`},
+		{Pointer: "/", Want: `0`},
+		{Pointer: "/a~1b", Want: `1`},
+		{Pointer: "/m~0n", Want: `8`},
+		{Pointer: "/nested/list/1/id", Want: `2`},
+	}
+	for _, d := range data {
+		got, err := Get(root, d.Pointer)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", d.Pointer, err)
+			continue
+		}
+		want, _ := New(strings.NewReader(d.Want)).Read()
+		if !Equal(want, got) {
+			t.Errorf("%s: want %v, got %v", d.Pointer, want, got)
+		}
+	}
+	if got, _ := Get(root, ""); !Equal(got, root) {
+		t.Errorf("empty pointer should give the root")
+	}
+	for _, p := range []string{"foo", "/missing", "/foo/2", "/foo/-", "/foo/01", "/foo/-1", "/foo/x", "/m~2n", "/a~", "/foo/0/bar"} {
+		if got, err := Get(root, p); err == nil {
+			t.Errorf("%s: invalid pointer resolved to %v", p, got)
+		}
+	}
+}
+
+func TestPointer_MultiObject(t *testing.T) {
+	read := func(str string) Element {
+		e, err := NewWithOptions(strings.NewReader(str), WithMultiObjects()).Read()
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %s", str, err)
+		}
+		return e
+	}
+	root := read(`{"a": 1, "b": {"c": true}, "a": 2}`)
+
+	data := []struct {
+		Pointer string
+		Want    Element
+	}{
+		{Pointer: "/a", Want: Array{NumberFrom(1), NumberFrom(2)}},
+		{Pointer: "/a/1", Want: NumberFrom(2)},
+		{Pointer: "/b/0/c/0", Want: Literal[bool]{Literal: true}},
+	}
+	for _, d := range data {
+		got, err := Get(root, d.Pointer)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", d.Pointer, err)
+			continue
+		}
+		if !Equal(d.Want, got) {
+			t.Errorf("%s: want %v, got %v", d.Pointer, d.Want, got)
+		}
+	}
+	if _, err := Get(root, "/missing"); err == nil {
+		t.Errorf("missing key resolved")
+	}
+
+	got, err := Set(root, "/a/-", NumberFrom(3))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if want := read(`{"a": 1, "b": {"c": true}, "a": 2, "a": 3}`); !Equal(want, got) {
+		t.Errorf("set: want %v, got %v", want, got)
+	}
+	if got, err = Set(root, "/a", NumberFrom(3)); err != nil || !Equal(read(`{"a": 3, "b": {"c": true}}`), got) {
+		t.Errorf("set: unexpected result %v (%v)", got, err)
+	}
+	want := read(`{"a": 1, "b": {"c": true}, "a": 2}`).(MultiObject)
+	want.Add("d", Object{"e": NumberFrom(3)})
+	if got, err = SetAll(root, "/d/-/e", NumberFrom(3)); err != nil || !Equal(want, got) {
+		t.Errorf("set all: unexpected result %v (%v)", got, err)
+	}
+	if got, err = Remove(root, "/a/0"); err != nil || !Equal(read(`{"b": {"c": true}, "a": 2}`), got) {
+		t.Errorf("remove: unexpected result %v (%v)", got, err)
+	}
+	if got, err = Remove(root, "/b"); err != nil || !Equal(read(`{"a": 1, "a": 2}`), got) {
+		t.Errorf("remove: unexpected result %v (%v)", got, err)
+	}
+	if !Equal(read(`{"a": 1, "b": {"c": true}, "a": 2}`), root) {
+		t.Errorf("original document modified")
+	}
+}
+
+func TestGetOr(t *testing.T) {
+	root, err := New(strings.NewReader(pointerDoc)).Read()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	def := String("default")
+	data := []struct {
+		Pointer string
+		Want    Element
+	}{
+		{Pointer: "/foo/1", Want: String("baz")},
+		{Pointer: "/missing", Want: def},
+		{Pointer: "/foo/9", Want: def},
+		{Pointer: "/foo/x", Want: def},
+		{Pointer: "/foo/0/bar", Want: def},
+		{Pointer: "foo", Want: def},
+	}
+	for _, d := range data {
+		if got := GetOr(root, d.Pointer, def); !Equal(got, d.Want) {
+			t.Errorf("%s: want %v, got %v", d.Pointer, d.Want, got)
+		}
+	}
+}
+
+func TestSet(t *testing.T) {
+	data := []struct {
+		Input   string
+		Pointer string
+		Value   string
+		Want    string
+		All     bool
+		Error   bool
+	}{
+		{Input: `{"a": 1}`, Pointer: "/a", Value: `2`, Want: `{"a": 2}`},
+		{Input: `{"a": 1}`, Pointer: "/b", Value: `2`, Want: `{"a": 1, "b": 2}`},
+		{Input: `{"a": [1, 2]}`, Pointer: "/a/0", Value: `3`, Want: `{"a": [3, 2]}`},
+		{Input: `{"a": [1, 2]}`, Pointer: "/a/-", Value: `3`, Want: `{"a": [1, 2, 3]}`},
+		{Input: `{"a": 1}`, Pointer: "", Value: `[]`, Want: `[]`},
+		{Input: `{"a": 1}`, Pointer: "/b/c", Value: `2`, Error: true},
+		{Input: `{"a": 1}`, Pointer: "/b/c", Value: `2`, Want: `{"a": 1, "b": {"c": 2}}`, All: true},
+		{Input: `{"a": []}`, Pointer: "/a/-/c", Value: `2`, Want: `{"a": [{"c": 2}]}`, All: true},
+		{Input: `{"a": [1]}`, Pointer: "/a/1", Value: `2`, Error: true},
+		{Input: `{"a": 1}`, Pointer: "/a/b", Value: `2`, Error: true, All: true},
+	}
+	for _, d := range data {
+		root, _ := New(strings.NewReader(d.Input)).Read()
+		value, _ := New(strings.NewReader(d.Value)).Read()
+		set := Set
+		if d.All {
+			set = SetAll
+		}
+		got, err := set(root, d.Pointer, value)
+		if d.Error {
+			if err == nil {
+				t.Errorf("%s: expected error, got %v", d.Pointer, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", d.Pointer, err)
+			continue
+		}
+		want, _ := New(strings.NewReader(d.Want)).Read()
+		if !Equal(want, got) {
+			t.Errorf("%s: want %v, got %v", d.Pointer, want, got)
+		}
+		if orig, _ := New(strings.NewReader(d.Input)).Read(); !Equal(orig, root) {
+			t.Errorf("%s: original document modified", d.Pointer)
+		}
+	}
+}
+
+func TestRemove(t *testing.T) {
+	data := []struct {
+		Input   string
+		Pointer string
+		Want    string
+		Error   bool
+	}{
+		{Input: `{"a": 1, "b": 2}`, Pointer: "/a", Want: `{"b": 2}`},
+		{Input: `{"a": [1, 2, 3]}`, Pointer: "/a/0", Want: `{"a": [2, 3]}`},
+		{Input: `{"a": [1, 2, 3]}`, Pointer: "/a/1", Want: `{"a": [1, 3]}`},
+		{Input: `{"a": [1, 2, 3]}`, Pointer: "/a/2", Want: `{"a": [1, 2]}`},
+		{Input: `[{"a": {"b": 1, "c": 2}}]`, Pointer: "/0/a/b", Want: `[{"a": {"c": 2}}]`},
+		{Input: `{"a": 1}`, Pointer: "/b", Error: true},
+		{Input: `{"a": [1]}`, Pointer: "/a/1", Error: true},
+		{Input: `{"a": [1]}`, Pointer: "/a/-", Error: true},
+		{Input: `{"a": 1}`, Pointer: "/a/b", Error: true},
+		{Input: `{"a": 1}`, Pointer: "", Error: true},
+	}
+	for _, d := range data {
+		root, _ := New(strings.NewReader(d.Input)).Read()
+		got, err := Remove(root, d.Pointer)
+		if d.Error {
+			if err == nil {
+				t.Errorf("%s: expected error, got %v", d.Pointer, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", d.Pointer, err)
+			continue
+		}
+		want, _ := New(strings.NewReader(d.Want)).Read()
+		if !Equal(want, got) {
+			t.Errorf("%s: want %v, got %v", d.Pointer, want, got)
+		}
+		if orig, _ := New(strings.NewReader(d.Input)).Read(); !Equal(orig, root) {
+			t.Errorf("%s: original document modified", d.Pointer)
+		}
+	}
+}
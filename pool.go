@@ -0,0 +1,42 @@
+package saj
+
+import (
+	"bytes"
+	"sync"
+)
+
+type Pool struct {
+	pool sync.Pool
+	opts []Option
+}
+
+func NewPool(opts ...Option) *Pool {
+	return &Pool{
+		opts: opts,
+	}
+}
+
+func (p *Pool) Get() *Reader {
+	if r, ok := p.pool.Get().(*Reader); ok {
+		return r
+	}
+	return NewWithOptions(nil, p.opts...)
+}
+
+func (p *Pool) Put(r *Reader) {
+	if r == nil {
+		return
+	}
+	r.Reset(nil)
+	r.shrink()
+	p.pool.Put(r)
+}
+
+func (p *Pool) Parse(b []byte) (Element, error) {
+	r := p.Get()
+	defer p.Put(r)
+
+	r.Reset(bytes.NewReader(b))
+	r.src = b
+	return r.Read()
+}
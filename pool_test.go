@@ -0,0 +1,53 @@
+package saj
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+)
+
+func TestPool(t *testing.T) {
+	p := NewPool(WithTrailingCommas())
+
+	var wg sync.WaitGroup
+	for i := 0; i < 16; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				doc := fmt.Sprintf(`{"id": %d, "tags": ["a", "b",],}`, i*100+j)
+				el, err := p.Parse([]byte(doc))
+				if err != nil {
+					t.Errorf("%s: unexpected error: %s", doc, err)
+					return
+				}
+				got, err := Get(el, "/id")
+				if err != nil {
+					t.Errorf("%s: unexpected error: %s", doc, err)
+					return
+				}
+				if v := Value(got); v != float64(i*100+j) {
+					t.Errorf("%s: want id %d, got %v", doc, i*100+j, v)
+					return
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if _, err := p.Parse([]byte(`{"a": }`)); err == nil {
+		t.Errorf("expected error but got none")
+	}
+	if _, err := p.Parse([]byte(`[1, 2]`)); err != nil {
+		t.Errorf("reader not reset after error: %s", err)
+	}
+}
+
+func TestPool_Zero(t *testing.T) {
+	var p Pool
+	r := p.Get()
+	p.Put(r)
+	if _, err := p.Parse([]byte(`"zero"`)); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+}
@@ -0,0 +1,133 @@
+package saj
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+type SyntaxError struct {
+	Offset int64
+	Err    error
+}
+
+func (e SyntaxError) Error() string {
+	return fmt.Sprintf("offset %d: %s", e.Offset, e.Err)
+}
+
+func (e SyntaxError) Unwrap() error {
+	return e.Err
+}
+
+type SyntaxErrors []SyntaxError
+
+func (e SyntaxErrors) Error() string {
+	var str strings.Builder
+	for i := range e {
+		if i > 0 {
+			str.WriteString("; ")
+		}
+		str.WriteString(e[i].Error())
+	}
+	return str.String()
+}
+
+// limitError is returned when one of the limits set on the reader is
+// exceeded. Contrary to syntax errors, it stops the parsing even when error
+// recovery is enabled.
+type limitError struct {
+	msg string
+}
+
+func (e limitError) Error() string {
+	return e.msg
+}
+
+// drain skips what is left of a value whose size exceeded the limit set
+// with SetMaxValueSize, so that the next call to Read starts on the
+// following value.
+func (r *Reader) drain() error {
+	var (
+		depth = r.overDepth
+		quote = r.quote
+	)
+	r.overflow = false
+	r.err = nil
+	r.quote = 0
+	r.reset()
+	for {
+		c, err := r.next()
+		if err != nil {
+			if errors.Is(err, io.EOF) && depth == 0 && quote == 0 {
+				return nil
+			}
+			return err
+		}
+		switch {
+		case quote != 0:
+			if c == backslash {
+				r.next()
+			} else if c == quote {
+				quote = 0
+				if depth == 0 {
+					return nil
+				}
+			}
+		case r.quoted(c):
+			quote = c
+		case isObject(c) || isArray(c):
+			depth++
+		case c == rcurly || c == rsquare:
+			if depth--; depth <= 0 {
+				return nil
+			}
+		case depth == 0 && isDelimiter(c):
+			r.reset()
+			return nil
+		}
+	}
+}
+
+// recover records err and skips the input up to the next comma or closing
+// bracket at the current depth. It reports whether the enclosing object or
+// array has been closed.
+func (r *Reader) recover(err error) (bool, error) {
+	var limit limitError
+	if !r.recovering || errors.Is(err, io.EOF) || errors.As(err, &limit) {
+		return false, err
+	}
+	r.errs = append(r.errs, SyntaxError{Offset: r.offset, Err: err})
+
+	var (
+		depth int
+		quote = r.quote
+	)
+	r.quote = 0
+	r.reset()
+	for {
+		c, err := r.next()
+		if err != nil {
+			return false, err
+		}
+		switch {
+		case quote != 0:
+			if c == backslash {
+				r.next()
+			} else if c == quote {
+				quote = 0
+			}
+		case r.quoted(c):
+			quote = c
+		case isObject(c) || isArray(c):
+			depth++
+		case c == rcurly || c == rsquare:
+			if depth == 0 {
+				return true, nil
+			}
+			depth--
+		case c == comma && depth == 0:
+			return false, nil
+		}
+	}
+}
@@ -0,0 +1,127 @@
+package saj
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestReader_ErrorRecovery(t *testing.T) {
+	data := []struct {
+		Input  string
+		Want   string
+		Errors []string
+	}{
+		{
+			Input:  `{"a": tru, "b": [1, 2,, 3], "c": "ok\e", "d": 4}`,
+			Want:   `{"b":[1,2,3],"d":4}`,
+			Errors: []string{"tru", "unexpected character ,", "unknown escape"},
+		},
+		{
+			Input:  `{"a": [1, 2}, "b": 3}`,
+			Want:   `{"a":[1,2],"b":3}`,
+			Errors: []string{"array: unexpected character }"},
+		},
+		{
+			Input:  `["\uD83D", {"x": [1, {]}, "y": 2}, 3]`,
+			Want:   `[{"x":[1,{}],"y":2},3]`,
+			Errors: []string{"incomplete surrogate pair", "key:", "array: unexpected character }"},
+		},
+		{
+			Input:  `[1, 2, "three" "four", 5]`,
+			Want:   `[1,2,"three",5]`,
+			Errors: []string{"array: unexpected character \""},
+		},
+		{
+			Input:  `[1, x, {"a": 1`,
+			Errors: []string{"unexpected character x", "unexpected EOF"},
+		},
+	}
+	for _, d := range data {
+		el, err := NewWithOptions(strings.NewReader(d.Input), WithErrorRecovery()).Read()
+		var errs SyntaxErrors
+		if !errors.As(err, &errs) {
+			t.Errorf("%s: expected syntax errors, got %v", d.Input, err)
+			continue
+		}
+		if len(errs) != len(d.Errors) {
+			t.Errorf("%s: want %d errors, got %d (%s)", d.Input, len(d.Errors), len(errs), errs)
+			continue
+		}
+		for i := range errs {
+			if !strings.Contains(errs[i].Error(), d.Errors[i]) {
+				t.Errorf("%s: error %d: want %q in %q", d.Input, i, d.Errors[i], errs[i])
+			}
+		}
+		if d.Want == "" {
+			continue
+		}
+		want, _ := New(strings.NewReader(d.Want)).Read()
+		if !Equal(el, want) {
+			t.Errorf("%s: want %s, got %v", d.Input, d.Want, Value(el))
+		}
+	}
+}
+
+func TestReader_ErrorRecoveryStop(t *testing.T) {
+	const input = `{"a": tru, "b": [1, 2,, 3]}`
+
+	_, err := New(strings.NewReader(input)).Read()
+	var errs SyntaxErrors
+	if err == nil || errors.As(err, &errs) {
+		t.Errorf("expected a single error without recovery, got %v", err)
+	}
+
+	_, err = NewWithOptions(strings.NewReader(`[[1], [2]]`), WithErrorRecovery(), WithMaxDepth(1)).Read()
+	if err == nil || errors.As(err, &errs) {
+		t.Errorf("limit errors should not be recovered, got %v", err)
+	}
+
+	r := NewWithOptions(strings.NewReader(`[1, x] {"a": 1}`), WithErrorRecovery())
+	if _, err := r.Read(); !errors.As(err, &errs) || len(errs) != 1 {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := r.Read(); err != nil {
+		t.Errorf("unexpected error on following value: %s", err)
+	}
+	if _, err := r.Read(); !errors.Is(err, io.EOF) {
+		t.Errorf("expected io.EOF, got %v", err)
+	}
+}
+
+func TestReader_MaxValueSize(t *testing.T) {
+	input := strings.Join([]string{
+		`{"a": 1}`,
+		`{"big": "` + strings.Repeat("x", 32) + `", "n": [1, {"}": "]"}]}`,
+		`[1, 2]`,
+		`"` + strings.Repeat("y\\\"", 16) + `"`,
+		strings.Repeat("9", 32),
+		`[[[[` + strings.Repeat(" ", 32) + `]]]]`,
+		`{"z": true}`,
+	}, "\n")
+
+	r := NewWithOptions(strings.NewReader(input), WithMaxValueSize(20), WithErrorRecovery())
+	for i, ok := range []bool{true, false, true, false, false, false, true} {
+		el, err := r.Read()
+		if ok && err != nil {
+			t.Fatalf("%d: unexpected error: %s", i, err)
+		}
+		if !ok && (err == nil || !strings.Contains(err.Error(), "value size limit exceeded (20 bytes)")) {
+			t.Fatalf("%d: expected size limit error, got %v (%v)", i, err, Value(el))
+		}
+	}
+	if _, err := r.Read(); !errors.Is(err, io.EOF) {
+		t.Errorf("expected io.EOF, got %v", err)
+	}
+
+	r = NewWithOptions(strings.NewReader(input), WithMaxValueSize(20))
+	if _, err := r.Read(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	for i := 0; i < 2; i++ {
+		if _, err := r.Read(); err == nil {
+			t.Errorf("expected error after size limit exceeded")
+		}
+	}
+}
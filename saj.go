@@ -6,8 +6,16 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"iter"
+	"math"
+	"math/big"
+	"sort"
 	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf16"
 	"unicode/utf8"
+	"unsafe"
 )
 
 type ElementType int
@@ -19,14 +27,40 @@ const (
 	TypeString
 	TypeBool
 	TypeNull
+	TypeInteger
 )
 
+func (t ElementType) String() string {
+	switch t {
+	case TypeObject:
+		return "object"
+	case TypeArray:
+		return "array"
+	case TypeNumber:
+		return "number"
+	case TypeString:
+		return "string"
+	case TypeBool:
+		return "bool"
+	case TypeNull:
+		return "null"
+	case TypeInteger:
+		return "integer"
+	default:
+		return "unknown"
+	}
+}
+
+func (t ElementType) accepts(other ElementType) bool {
+	return t == other || (t == TypeNumber && other == TypeInteger)
+}
+
 type Element interface {
 	Type() ElementType
 }
 
 type Primitive interface {
-	float64 | bool | string | struct{}
+	float64 | int64 | bool | string | struct{}
 }
 
 type Literal[T Primitive] struct {
@@ -39,85 +73,1149 @@ func String(str string) Literal[string] {
 	}
 }
 
-func Number(str string) (Literal[float64], error) {
-	v, err := strconv.ParseFloat(str, 64)
-	lit := Literal[float64]{
-		Literal: v,
+func Number(str string) (Literal[float64], error) {
+	v, err := strconv.ParseFloat(str, 64)
+	lit := Literal[float64]{
+		Literal: v,
+	}
+	return lit, err
+}
+
+func Integer(str string) (Literal[int64], error) {
+	v, err := strconv.ParseInt(str, 10, 64)
+	lit := Literal[int64]{
+		Literal: v,
+	}
+	return lit, err
+}
+
+func NumberFrom(v float64) Literal[float64] {
+	return Literal[float64]{
+		Literal: v,
+	}
+}
+
+func IntFrom(v int64) Literal[int64] {
+	return Literal[int64]{
+		Literal: v,
+	}
+}
+
+func Bool(str string) (Literal[bool], error) {
+	b, err := strconv.ParseBool(str)
+	lit := Literal[bool]{
+		Literal: b,
+	}
+	return lit, err
+}
+
+func Null() Literal[struct{}] {
+	return Literal[struct{}]{}
+}
+
+func (i Literal[T]) Value() any {
+	return i.Literal
+}
+
+func (i Literal[T]) withRaw(raw string) Element {
+	return RawLiteral[T]{
+		Literal: i,
+		raw:     raw,
+	}
+}
+
+// Bytes returns a copy of the UTF-8 text of a string literal and nil for
+// other literals. Reader.Bytes avoids the copy for the strings read with
+// SetZeroCopyStrings from the slice given to NewBytes.
+func (i Literal[T]) Bytes() []byte {
+	str, ok := any(i.Literal).(string)
+	if !ok || str == "" {
+		return nil
+	}
+	return []byte(str)
+}
+
+func (i Literal[T]) MarshalText() ([]byte, error) {
+	switch v := any(i.Literal).(type) {
+	case string:
+		return []byte(v), nil
+	case float64:
+		str, err := formatNumber(v)
+		return []byte(str), err
+	case int64:
+		return []byte(strconv.FormatInt(v, 10)), nil
+	case bool:
+		return []byte(strconv.FormatBool(v)), nil
+	default:
+		return []byte(kwNull), nil
+	}
+}
+
+func (i *Literal[T]) UnmarshalText(text []byte) error {
+	var lit Literal[T]
+	switch v := any(&lit.Literal).(type) {
+	case *string:
+		*v = string(text)
+	case *float64:
+		n, err := Number(string(text))
+		if err != nil || math.IsNaN(n.Literal) || math.IsInf(n.Literal, 0) {
+			return fmt.Errorf("number: invalid text %q", text)
+		}
+		*v = n.Literal
+	case *int64:
+		n, err := Integer(string(text))
+		if err != nil {
+			return fmt.Errorf("integer: invalid text %q", text)
+		}
+		*v = n.Literal
+	case *bool:
+		b, err := Bool(string(text))
+		if err != nil {
+			return fmt.Errorf("bool: invalid text %q", text)
+		}
+		*v = b.Literal
+	default:
+		if string(text) != kwNull {
+			return fmt.Errorf("null: invalid text %q", text)
+		}
+	}
+	*i = lit
+	return nil
+}
+
+func (i Literal[T]) Narrowest() any {
+	f, ok := any(i.Literal).(float64)
+	if !ok {
+		return i.Literal
+	}
+	if f == math.Trunc(f) && f >= math.MinInt64 && f < math.MaxInt64 {
+		return int64(f)
+	}
+	return f
+}
+
+// RawLiteral is a scalar read with SetPreserveRaw. Besides the parsed
+// Literal, it keeps the text the value was written with in the input.
+type RawLiteral[T Primitive] struct {
+	Literal[T]
+	raw string
+}
+
+func (i RawLiteral[T]) Raw() string {
+	return i.raw
+}
+
+func (i RawLiteral[T]) HadFraction() bool {
+	return i.number() && strings.ContainsRune(i.raw, dot)
+}
+
+func (i RawLiteral[T]) HadExponent() bool {
+	return i.number() && strings.ContainsAny(i.raw, "eE")
+}
+
+// Narrowest is like the method of Literal, but also gives an int64 for
+// integers too large to be represented exactly by a float64.
+func (i RawLiteral[T]) Narrowest() any {
+	if _, ok := any(i.Literal.Literal).(float64); ok {
+		if n, err := strconv.ParseInt(i.raw, 10, 64); err == nil {
+			return n
+		}
+	}
+	return i.Literal.Narrowest()
+}
+
+func (i RawLiteral[T]) number() bool {
+	t := i.Type()
+	return t == TypeNumber || t == TypeInteger
+}
+
+func (i RawLiteral[T]) plain() Element {
+	return i.Literal
+}
+
+// plain returns the Literal held by a RawLiteral, el itself otherwise.
+func plain(el Element) Element {
+	if i, ok := el.(interface{ plain() Element }); ok {
+		return i.plain()
+	}
+	return el
+}
+
+// AsInt64 and AsBigInt parse the content of a string literal as an integer,
+// for APIs sending large numbers as strings to avoid losing precision.
+func (i Literal[T]) AsInt64() (int64, error) {
+	str, err := i.integer()
+	if err != nil {
+		return 0, err
+	}
+	n, err := strconv.ParseInt(str, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("literal: %q is not a valid int64", str)
+	}
+	return n, nil
+}
+
+func (i Literal[T]) AsBigInt() (*big.Int, error) {
+	str, err := i.integer()
+	if err != nil {
+		return nil, err
+	}
+	n, ok := new(big.Int).SetString(str, 10)
+	if !ok {
+		return nil, fmt.Errorf("literal: %q is not a valid integer", str)
+	}
+	return n, nil
+}
+
+// Parse reads the content of a string literal as a JSON document, as found
+// in payloads embedding JSON encoded a second time.
+func (i Literal[T]) Parse(opts ...Option) (Element, error) {
+	str, ok := any(i.Literal).(string)
+	if !ok {
+		return nil, fmt.Errorf("literal: %s is not a string", i.Type())
+	}
+	r := NewWithOptions(strings.NewReader(str), opts...)
+	el, err := r.Read()
+	if err != nil {
+		return nil, fmt.Errorf("literal: %w", err)
+	}
+	if _, err := r.Peek(); !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("literal: unexpected data after value")
+	}
+	return el, nil
+}
+
+func (i Literal[T]) integer() (string, error) {
+	str, ok := any(i.Literal).(string)
+	if !ok {
+		return "", fmt.Errorf("literal: %s is not a string", i.Type())
+	}
+	return str, nil
+}
+
+func (i Literal[T]) Type() ElementType {
+	switch any(i.Literal).(type) {
+	case string:
+		return TypeString
+	case bool:
+		return TypeBool
+	case float64:
+		return TypeNumber
+	case int64:
+		return TypeInteger
+	default:
+		return TypeNull
+	}
+}
+
+type Array []Element
+
+func (_ Array) Type() ElementType {
+	return TypeArray
+}
+
+func (a Array) All() iter.Seq2[int, Element] {
+	return func(yield func(int, Element) bool) {
+		for i, el := range a {
+			if !yield(i, el) {
+				return
+			}
+		}
+	}
+}
+
+func (a Array) Concat(others ...Array) Array {
+	n := len(a)
+	for _, o := range others {
+		n += len(o)
+	}
+	arr := make(Array, 0, n)
+	arr = append(arr, a...)
+	for _, o := range others {
+		arr = append(arr, o...)
+	}
+	return arr
+}
+
+type Object map[string]Element
+
+func (_ Object) Type() ElementType {
+	return TypeObject
+}
+
+func (o Object) Merge(other Object) Object {
+	obj := make(Object, len(o)+len(other))
+	for k, v := range o {
+		obj[k] = v
+	}
+	for k, v := range other {
+		obj[k] = v
+	}
+	return obj
+}
+
+func (o Object) Set(key string, val Element) {
+	o[key] = val
+}
+
+func (o Object) Delete(key string) {
+	delete(o, key)
+}
+
+// GetInsensitive looks key up ignoring case. An exact match is preferred;
+// otherwise, when several keys only differ by their case, the first of them
+// in sorted order is used.
+func (o Object) GetInsensitive(key string) (Element, bool) {
+	if el, ok := o[key]; ok {
+		return el, true
+	}
+	var (
+		found string
+		ok    bool
+	)
+	for k := range o {
+		if strings.EqualFold(k, key) && (!ok || k < found) {
+			found, ok = k, true
+		}
+	}
+	return o[found], ok
+}
+
+// All iterates over the members of o sorted by key.
+func (o Object) All() iter.Seq2[string, Element] {
+	return func(yield func(string, Element) bool) {
+		for _, k := range o.SortedKeys() {
+			if !yield(k, o[k]) {
+				return
+			}
+		}
+	}
+}
+
+func (o Object) SortedKeys() []string {
+	keys := make([]string, 0, len(o))
+	for k := range o {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// MultiObject is an object keeping every value given to a key, in the order
+// they appear in the input. Walk, Flatten, Value, Decode and Schema see it as
+// an object holding the values of each key in an array.
+type MultiObject map[string][]Element
+
+func (_ MultiObject) Type() ElementType {
+	return TypeObject
+}
+
+func (m MultiObject) Add(key string, val Element) {
+	m[key] = append(m[key], val)
+}
+
+// Object flattens m, keeping the last value of each key.
+func (m MultiObject) Object() Object {
+	obj := make(Object, len(m))
+	for k, vs := range m {
+		if len(vs) > 0 {
+			obj[k] = vs[len(vs)-1]
+		}
+	}
+	return obj
+}
+
+// lists views m as an object holding the values of each key in an array.
+func (m MultiObject) lists() Object {
+	obj := make(Object, len(m))
+	for k, vs := range m {
+		obj[k] = Array(vs)
+	}
+	return obj
+}
+
+func (m MultiObject) SortedKeys() []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// AsObject returns el as an Object. A MultiObject is returned as an object
+// holding the values of each key in an array.
+func AsObject(el Element) (Object, bool) {
+	if m, ok := el.(MultiObject); ok {
+		return m.lists(), true
+	}
+	obj, ok := el.(Object)
+	return obj, ok
+}
+
+func AsArray(el Element) (Array, bool) {
+	arr, ok := el.(Array)
+	return arr, ok
+}
+
+func AsString(el Element) (string, bool) {
+	i, ok := plain(el).(Literal[string])
+	return i.Literal, ok
+}
+
+func AsNumber(el Element) (float64, bool) {
+	el = plain(el)
+	if i, ok := el.(Literal[int64]); ok {
+		return float64(i.Literal), true
+	}
+	i, ok := el.(Literal[float64])
+	return i.Literal, ok
+}
+
+func AsInteger(el Element) (int64, bool) {
+	i, ok := plain(el).(Literal[int64])
+	return i.Literal, ok
+}
+
+func AsBool(el Element) (bool, bool) {
+	i, ok := plain(el).(Literal[bool])
+	return i.Literal, ok
+}
+
+func IsNull(el Element) bool {
+	_, ok := plain(el).(Literal[struct{}])
+	return ok
+}
+
+func IsObject(el Element) bool {
+	switch el.(type) {
+	case Object, MultiObject:
+		return true
+	default:
+		return false
+	}
+}
+
+func IsArray(el Element) bool {
+	_, ok := el.(Array)
+	return ok
+}
+
+func IsScalar(el Element) bool {
+	switch plain(el).(type) {
+	case Literal[string], Literal[float64], Literal[int64], Literal[bool], Literal[struct{}]:
+		return true
+	default:
+		return false
+	}
+}
+
+type UTF8Policy int
+
+const (
+	PolicyError UTF8Policy = iota
+	PolicyReplace
+)
+
+type Span struct {
+	Start int64
+	End   int64
+}
+
+type TopLevelMode int
+
+const (
+	AllowAnyValue TopLevelMode = iota
+	RequireContainer
+)
+
+// Trivia holds the whitespace surrounding a value in the source document.
+// Before and After surround the value (or the whole member for values of an
+// object), Key and Colon follow the key and the colon of an object member.
+// Close is the whitespace before the closing bracket of a container that is
+// not attached to any of its values and Keys keeps the original order of the
+// keys of an object.
+type Trivia struct {
+	Before string
+	Key    string
+	Colon  string
+	After  string
+	Close  string
+	Keys   []string
+
+	Comments []string
+}
+
+var errEmpty = errors.New("empty")
+
+type Reader struct {
+	in    io.Reader
+	rs    *bufio.Reader
+	buf   bytes.Buffer
+	arena []byte
+	depth int
+	err   error
+	count int
+	into  Element
+
+	started bool
+	checked bool
+	more    bool
+	tail    bool
+
+	current Element
+	failure error
+
+	recovering bool
+	quote      rune
+	errs       SyntaxErrors
+
+	measuring  bool
+	valueStart int64
+	overflow   bool
+	overDepth  int
+
+	offset  int64
+	last    rune
+	size    int
+	width   int
+	pending bool
+
+	path   []string
+	spans  map[string]Span
+	trivia map[string]Trivia
+	member Trivia
+	ws     bytes.Buffer
+	note   bytes.Buffer
+	notes  []string
+
+	capturing bool
+	rawbuf    bytes.Buffer
+
+	src []byte
+
+	tokenSpans bool
+	token      Span
+	tokenStart int64
+	keySpan    Span
+
+	resumable bool
+	journal   bytes.Buffer
+	eof       bool
+
+	maxDepth     int
+	maxElements  int
+	maxNumber    int
+	maxKeys      int
+	maxArray     int
+	maxValue     int
+	invalid      UTF8Policy
+	topLevel     TopLevelMode
+	comments     bool
+	keepComments bool
+	trailing     bool
+	commas       bool
+	emptyNull    bool
+	raw          bool
+	single       bool
+	unquoted     bool
+	strict       bool
+	zeroCopy     bool
+	zeroStrings  bool
+	integers     bool
+	disallow     bool
+	multi        bool
+	duplicate    func(string, Element, Element) (Element, error)
+	keyFilter    func(string) (string, bool)
+	normalizer   func(string) string
+	numberParser func(string) (Element, error)
+	valueFilter  func(string, Element) (Element, error)
+	stringSink   func(string) io.Writer
+	progress     func(int64)
+	every        int64
+	mark         int64
+}
+
+func New(r io.Reader) *Reader {
+	return NewWithOptions(r)
+}
+
+func NewWithOptions(r io.Reader, opts ...Option) *Reader {
+	rs := Reader{
+		in: r,
+		rs: bufio.NewReader(r),
+	}
+	for _, o := range opts {
+		o(&rs)
+	}
+	return &rs
+}
+
+func NewBytes(b []byte, opts ...Option) *Reader {
+	rs := NewWithOptions(bytes.NewReader(b), opts...)
+	rs.src = b
+	return rs
+}
+
+func ReparseSpan(src io.ReaderAt, span Span, opts ...Option) (Element, error) {
+	if span.Start < 0 || span.End < span.Start {
+		return nil, fmt.Errorf("span: invalid range [%d:%d]", span.Start, span.End)
+	}
+	rs := NewWithOptions(io.NewSectionReader(src, span.Start, span.End-span.Start), opts...)
+	el, err := rs.Read()
+	if err != nil {
+		return nil, err
+	}
+	if c, err := rs.next(); err == nil {
+		return nil, fmt.Errorf("span: unexpected character %c after value", c)
+	}
+	return el, nil
+}
+
+const maxRetainedBuffer = 64 << 10
+
+func (r *Reader) Reset(rs io.Reader) {
+	r.in = rs
+	r.rs.Reset(rs)
+	r.src = nil
+	r.journal.Reset()
+	r.eof = false
+	r.buf.Reset()
+	r.depth = 0
+	r.err = nil
+	r.count = 0
+	r.started = false
+	r.checked = false
+	r.more = false
+	r.current = nil
+	r.failure = nil
+	r.quote = 0
+	r.errs = nil
+	r.measuring = false
+	r.overflow = false
+	r.offset = 0
+	r.mark = r.every
+	r.size = 0
+	r.pending = false
+	r.capturing = false
+	r.path = r.path[:0]
+	if r.spans != nil {
+		r.spans = make(map[string]Span)
+	}
+	if r.trivia != nil {
+		r.trivia = make(map[string]Trivia)
+	}
+	r.ws.Reset()
+	r.note.Reset()
+	r.notes = nil
+}
+
+func (r *Reader) Release() {
+	r.Reset(nil)
+	r.shrink()
+	r.duplicate = nil
+	r.keyFilter = nil
+	r.normalizer = nil
+	r.numberParser = nil
+	r.valueFilter = nil
+	r.stringSink = nil
+	r.progress = nil
+}
+
+// shrink drops the internal buffers that grew past maxRetainedBuffer so
+// that a reader kept for reuse does not pin their peak size.
+func (r *Reader) shrink() {
+	for _, b := range []*bytes.Buffer{&r.buf, &r.journal, &r.rawbuf, &r.ws, &r.note} {
+		if b.Cap() > maxRetainedBuffer {
+			*b = bytes.Buffer{}
+		}
+	}
+}
+
+func (r *Reader) SetMaxDepth(n int) {
+	r.maxDepth = n
+}
+
+func (r *Reader) SetMaxElements(n int) {
+	r.maxElements = n
+}
+
+func (r *Reader) SetMaxObjectKeys(n int) {
+	r.maxKeys = n
+}
+
+func (r *Reader) SetMaxArrayLength(n int) {
+	r.maxArray = n
+}
+
+func (r *Reader) SetMaxValueSize(n int) {
+	r.maxValue = n
+}
+
+func (r *Reader) SetMaxNumberLength(n int) {
+	r.maxNumber = n
+}
+
+func (r *Reader) SetInvalidUTF8Policy(policy UTF8Policy) {
+	r.invalid = policy
+}
+
+func (r *Reader) SetRecordSpans(record bool) {
+	if !record {
+		r.spans = nil
+	} else if r.spans == nil {
+		r.spans = make(map[string]Span)
+	}
+}
+
+func (r *Reader) Spans() map[string]Span {
+	return r.spans
+}
+
+func (r *Reader) SetTopLevelMode(mode TopLevelMode) {
+	r.topLevel = mode
+}
+
+// SetPreserveRaw makes scalars read as RawLiteral, keeping their source text
+// for the encoder to write them back unchanged.
+func (r *Reader) SetPreserveRaw(preserve bool) {
+	r.raw = preserve
+}
+
+func (r *Reader) SetEmptyAsNull(null bool) {
+	r.emptyNull = null
+}
+
+func (r *Reader) SetPreserveFormat(preserve bool) {
+	if !preserve {
+		r.trivia = nil
+	} else if r.trivia == nil {
+		r.trivia = make(map[string]Trivia)
+	}
+}
+
+// SetPreserveComments allows comments and keeps them in the trivia recorded
+// by SetPreserveFormat, which it enables: the encoder writes them back with
+// the surrounding whitespace, and the text of the comments found before a
+// value is listed in the Comments of its trivia.
+func (r *Reader) SetPreserveComments(preserve bool) {
+	r.keepComments = preserve
+	if preserve {
+		r.comments = true
+		r.SetPreserveFormat(true)
+	}
+}
+
+func (r *Reader) Trivia() map[string]Trivia {
+	return r.trivia
+}
+
+func (r *Reader) SetStrictWhitespace(strict bool) {
+	r.strict = strict
+}
+
+// SetZeroCopyKeys makes object keys share memory with the slice given to
+// NewBytes when they contain no escape sequence. The slice must then not be
+// modified for as long as the keys are in use. It has no effect on readers
+// created with New.
+func (r *Reader) SetZeroCopyKeys(zero bool) {
+	r.zeroCopy = zero
+}
+
+// SetZeroCopyStrings is like SetZeroCopyKeys but for string values.
+func (r *Reader) SetZeroCopyStrings(zero bool) {
+	r.zeroStrings = zero
+}
+
+// Bytes returns the bytes of str without copying them when str shares memory
+// with the slice given to NewBytes, as zero-copy keys and strings do. The
+// result is then a sub-slice of that source. Any other string is copied.
+func (r *Reader) Bytes(str string) []byte {
+	if str == "" || len(str) > len(r.src) {
+		return []byte(str)
+	}
+	var (
+		base = uintptr(unsafe.Pointer(unsafe.SliceData(r.src)))
+		ptr  = uintptr(unsafe.Pointer(unsafe.StringData(str)))
+	)
+	if ptr < base || ptr-base > uintptr(len(r.src)-len(str)) {
+		return []byte(str)
+	}
+	off := int(ptr - base)
+	return r.src[off : off+len(str) : off+len(str)]
+}
+
+// SetResumable makes Read return io.ErrUnexpectedEOF when the input ends in
+// the middle of a value and keep what was consumed so far: once more data is
+// available from the underlying reader, the next call to Read parses the same
+// value again from its start. Since more digits or letters could follow, a
+// number, true, false or null is only complete once a delimiter is read. A
+// comment cut at the end of the input is read again in the same way.
+func (r *Reader) SetResumable(resumable bool) {
+	r.resumable = resumable
+}
+
+func (r *Reader) SetTokenSpans(record bool) {
+	r.tokenSpans = record
+}
+
+func (r *Reader) SetComments(allow bool) {
+	r.comments = allow
+}
+
+func (r *Reader) SetTrailingCommas(allow bool) {
+	r.trailing = allow
+}
+
+func (r *Reader) SetErrorRecovery(recover bool) {
+	r.recovering = recover
+}
+
+func (r *Reader) SetCommaSeparatedValues(allow bool) {
+	r.commas = allow
+}
+
+func (r *Reader) SetSingleQuotes(allow bool) {
+	r.single = allow
+}
+
+func (r *Reader) SetUnquotedKeys(allow bool) {
+	r.unquoted = allow
+}
+
+// SetMultiObjects makes objects read as MultiObject so that the values of
+// duplicate keys are all kept in order. The duplicate key function is not
+// called in this mode.
+func (r *Reader) SetMultiObjects(multi bool) {
+	r.multi = multi
+}
+
+func (r *Reader) SetDuplicateKeyFunc(fn func(key string, old, new Element) (Element, error)) {
+	r.duplicate = fn
+}
+
+func (r *Reader) SetKeyFilter(fn func(key string) (string, bool)) {
+	r.keyFilter = fn
+}
+
+func (r *Reader) SetKeyNormalizer(fn func(string) string) {
+	r.normalizer = fn
+}
+
+func (r *Reader) SetDisallowUnknownFields(disallow bool) {
+	r.disallow = disallow
+}
+
+func (r *Reader) SetIntegers(integers bool) {
+	r.integers = integers
+}
+
+func (r *Reader) SetNumberParser(fn func(raw string) (Element, error)) {
+	r.numberParser = fn
+}
+
+func (r *Reader) SetValueFilter(fn func(path string, el Element) (Element, error)) {
+	r.valueFilter = fn
+}
+
+func (r *Reader) SetStringSink(fn func(key string) io.Writer) {
+	r.stringSink = fn
+}
+
+func (r *Reader) SetProgressFunc(everyBytes int, fn func(bytesRead int64)) {
+	if everyBytes <= 0 {
+		fn = nil
+	}
+	r.progress = fn
+	if fn == nil {
+		return
+	}
+	r.every = int64(everyBytes)
+	r.mark = r.offset - r.offset%r.every + r.every
+}
+
+func (r *Reader) Read() (Element, error) {
+	return r.readWith(r.read)
+}
+
+// Next reads the next top-level value, made available by Value. It returns
+// false once the input is exhausted or an error occurs, which Err then
+// reports. A value cut short by the end of the input is reported as
+// io.ErrUnexpectedEOF.
+func (r *Reader) Next() bool {
+	if r.failure != nil {
+		return false
+	}
+	r.current, r.failure = r.Read()
+	if r.failure != nil {
+		r.current = nil
+		return false
+	}
+	return true
+}
+
+func (r *Reader) Value() Element {
+	return r.current
+}
+
+// Err returns the error that stopped Next, or nil if the end of the input was
+// reached.
+func (r *Reader) Err() error {
+	if errors.Is(r.failure, io.EOF) {
+		return nil
+	}
+	return r.failure
+}
+
+// ReadWith is like Read but stores the text of keys, strings and numbers in
+// scratch instead of allocating it piece by piece. The strings of the returned
+// element alias scratch: they are only valid until scratch is modified or
+// given to another call. Once scratch is full, the remaining text is allocated
+// as Read does.
+func (r *Reader) ReadWith(scratch []byte) (Element, error) {
+	r.arena = scratch[:0]
+	defer func() {
+		r.arena = nil
+	}()
+	return r.Read()
+}
+
+func (r *Reader) ReadIterative() (Element, error) {
+	return r.readWith(r.iterate)
+}
+
+func (r *Reader) readWith(parse func() (Element, error)) (Element, error) {
+	if r.resumable {
+		r.journal.Reset()
+		r.eof = false
+		if r.pending {
+			r.journal.WriteRune(r.last)
+		}
+	}
+	r.count = 0
+	r.quote = 0
+	r.errs = nil
+	if r.spans != nil {
+		r.spans = make(map[string]Span)
+	}
+	if r.trivia != nil {
+		r.trivia = make(map[string]Trivia)
+	}
+	r.skipBlank()
+	if err := r.separator(); err != nil {
+		return nil, err
+	}
+	if !r.started && r.emptyNull {
+		if _, err := r.next(); errors.Is(err, io.EOF) {
+			r.started = true
+			return Null(), nil
+		}
+		r.reset()
+	}
+	r.started = true
+	if r.topLevel == RequireContainer {
+		if t, err := r.Peek(); err == nil && t != TypeObject && t != TypeArray {
+			return nil, fmt.Errorf("read: top-level value must be an object or an array, got %s", t)
+		}
+	}
+	if r.strict {
+		if err := r.blank("leading"); err != nil {
+			return nil, err
+		}
+	}
+	before := r.take()
+	notes := r.takeNotes()
+	r.measuring, r.valueStart = r.maxValue > 0, r.offset
+	el, err := parse()
+	r.measuring = false
+	if errors.Is(err, io.EOF) && r.count > 0 {
+		err = io.ErrUnexpectedEOF
+	}
+	if r.overflow {
+		err = r.err
+		if r.recovering {
+			if e := r.drain(); e != nil {
+				err = e
+			}
+		}
+	}
+	if err != nil && r.resumable {
+		err = r.suspend(err)
+	}
+	if err == nil && r.strict {
+		if err = r.blank("trailing"); err != nil {
+			el = nil
+		}
+	}
+	r.more = err == nil
+	if err == nil && r.trivia != nil {
+		after := r.take()
+		r.setTrivia(func(t *Trivia) {
+			t.Before, t.After, t.Comments = before, after, notes
+		})
+	}
+	if len(r.errs) > 0 {
+		if errors.Is(err, io.EOF) {
+			err = io.ErrUnexpectedEOF
+		}
+		if err != nil {
+			r.errs = append(r.errs, SyntaxError{Offset: r.offset, Err: err})
+		}
+		return el, r.errs
+	}
+	return el, err
+}
+
+// ReadValue reads a value and returns the bytes following it that were
+// already buffered, as Buffered does, starting with the blanks right after
+// the value. Bytes not yet buffered are left in the underlying reader. A
+// value cut short by the end of the input is reported as
+// io.ErrUnexpectedEOF.
+func (r *Reader) ReadValue() (Element, []byte, error) {
+	r.tail = true
+	defer func() {
+		r.tail = false
+	}()
+	el, err := r.Read()
+	if err != nil {
+		return nil, nil, err
 	}
-	return lit, err
+	rest, err := r.Buffered()
+	if err != nil {
+		return nil, nil, err
+	}
+	return el, rest, nil
 }
 
-func Bool(str string) (Literal[bool], error) {
-	b, err := strconv.ParseBool(str)
-	lit := Literal[bool]{
-		Literal: b,
+func (r *Reader) Buffered() ([]byte, error) {
+	b, err := r.rs.Peek(r.rs.Buffered())
+	if err != nil {
+		return nil, err
 	}
-	return lit, err
+	var buf []byte
+	if r.pending {
+		buf = utf8.AppendRune(buf, r.last)
+	}
+	return append(buf, b...), nil
 }
 
-func Null() Literal[struct{}] {
-	return Literal[struct{}]{}
+func (r *Reader) LastCount() int {
+	return r.count
 }
 
-func (i Literal[T]) Type() ElementType {
-	switch any(i.Literal).(type) {
-	case string:
-		return TypeString
-	case bool:
-		return TypeBool
-	case float64:
-		return TypeNumber
-	default:
-		return TypeNull
+// PeekType returns the type of the first value of rs. When rs is an
+// io.Seeker, its offset is restored before returning so the document can be
+// parsed afterwards. Otherwise the bytes read to classify the value, which
+// may be more than the value itself because of buffering, are lost.
+func PeekType(rs io.Reader, opts ...Option) (ElementType, error) {
+	var (
+		pos     int64
+		err     error
+		seek, _ = rs.(io.Seeker)
+	)
+	if seek != nil {
+		if pos, err = seek.Seek(0, io.SeekCurrent); err != nil {
+			return 0, err
+		}
 	}
+	t, err := NewWithOptions(rs, opts...).Peek()
+	if seek != nil {
+		if _, err := seek.Seek(pos, io.SeekStart); err != nil {
+			return 0, err
+		}
+	}
+	return t, err
 }
 
-type Array []Element
-
-func (_ Array) Type() ElementType {
-	return TypeArray
-}
-
-type Object map[string]Element
-
-func (_ Object) Type() ElementType {
-	return TypeObject
+func (r *Reader) Peek() (ElementType, error) {
+	r.skipBlank()
+	if r.depth == 0 {
+		if err := r.separator(); err != nil {
+			return 0, err
+		}
+	}
+	c, err := r.next()
+	if err != nil {
+		return 0, err
+	}
+	r.reset()
+	switch {
+	case r.quoted(c):
+		return TypeString, nil
+	case isObject(c):
+		return TypeObject, nil
+	case isArray(c):
+		return TypeArray, nil
+	case isDigit(c) || isMinus(c):
+		return TypeNumber, nil
+	case c == 't' || c == 'f':
+		return TypeBool, nil
+	case c == 'n':
+		return TypeNull, nil
+	default:
+		return 0, fmt.Errorf("peek: unexpected character %c", c)
+	}
 }
 
-var errEmpty = errors.New("empty")
-
-type Reader struct {
-	rs    *bufio.Reader
-	buf   bytes.Buffer
-	depth int
+func (r *Reader) ReadInto(dst Element) (Element, error) {
+	defer func() {
+		r.into = nil
+	}()
+	switch dst := dst.(type) {
+	case Object:
+		for k := range dst {
+			delete(dst, k)
+		}
+		r.into = dst
+	case Array:
+		for i := range dst {
+			dst[i] = nil
+		}
+		r.into = dst[:0]
+	}
+	return r.Read()
 }
 
-func New(r io.Reader) *Reader {
-	rs := Reader{
-		rs: bufio.NewReader(r),
+func (r *Reader) newObject() Object {
+	if obj, ok := r.into.(Object); ok && r.depth == 1 {
+		r.into = nil
+		return obj
 	}
-	rs.skipBlank()
-	return &rs
+	return make(Object)
 }
 
-func (r *Reader) Read() (Element, error) {
-	return r.read()
+func (r *Reader) newArray() Array {
+	if arr, ok := r.into.(Array); ok && r.depth == 1 {
+		r.into = nil
+		return arr
+	}
+	return nil
 }
 
-func (r *Reader) read() (Element, error) {
+func (r *Reader) read() (el Element, err error) {
 	defer func() {
 		r.buf.Reset()
-		r.skipBlank()
+		if err == nil && !(r.tail && r.depth == 0) {
+			r.skipBlank()
+		}
 	}()
 
 	c, err := r.next()
 	if err != nil {
 		return nil, err
 	}
-	var el Element
+	if !isBlank(c) {
+		if err = r.incr(); err != nil {
+			return nil, err
+		}
+	}
+	start := r.offset - int64(r.size)
+	if r.raw && !isObject(c) && !isArray(c) && !isBlank(c) {
+		r.capture(c)
+	}
 	switch {
-	case isString(c):
-		el, err = r.literal()
+	case r.quoted(c):
+		el, err = r.literalTo(c, r.sink())
 	case isObject(c):
 		el, err = r.object()
 	case isArray(c):
@@ -125,6 +1223,8 @@ func (r *Reader) read() (Element, error) {
 	case isDigit(c) || isMinus(c):
 		r.reset()
 		el, err = r.number()
+	case c == plus:
+		err = fmt.Errorf("number: unexpected leading '+'")
 	case isIdent(c):
 		r.reset()
 		el, err = r.identifier()
@@ -132,266 +1232,630 @@ func (r *Reader) read() (Element, error) {
 		r.skipBlank()
 		return r.read()
 	default:
-		err = fmt.Errorf("read: unexpected character %c", c)
+		err = r.unexpected(c)
+	}
+	if r.capturing {
+		el = r.captured(el)
+	}
+	if err == nil && r.valueFilter != nil {
+		if t := el.Type(); t != TypeObject && t != TypeArray {
+			el, err = r.valueFilter(joinPointer(r.path), el)
+		}
+	}
+	if err == nil && r.spans != nil {
+		r.spans[joinPointer(r.path)] = Span{
+			Start: start,
+			End:   r.offset,
+		}
 	}
 	return el, err
 }
 
 func (r *Reader) object() (Element, error) {
-	r.enter()
+	err := r.enter()
 	defer r.leave()
+	if err != nil {
+		return nil, err
+	}
 
-	obj := make(Object)
-	for {
-		key, err := r.key()
+	var (
+		obj   Object
+		multi MultiObject
+		keys  []string
+	)
+	if r.multi {
+		multi = make(MultiObject)
+	} else {
+		obj = r.newObject()
+	}
+	for n := 1; ; n++ {
+		done, err := r.property(obj, multi, &keys, n)
 		if err != nil {
-			if errors.Is(err, errEmpty) {
-				break
-			}
-			return nil, err
+			done, err = r.recover(err)
 		}
-		val, err := r.read()
 		if err != nil {
 			return nil, err
 		}
-		obj[key] = val
+		if done {
+			break
+		}
+	}
+	r.layout(keys)
+	if multi != nil {
+		return multi, nil
+	}
+	return obj, nil
+}
 
-		c, err := r.next()
-		if err != nil {
-			return nil, err
+func (r *Reader) property(obj Object, multi MultiObject, keys *[]string, n int) (bool, error) {
+	key, err := r.key()
+	if err != nil {
+		if errors.Is(err, errEmpty) {
+			return true, r.closing(rcurly, "object")
 		}
-		if c == rcurly {
-			return obj, nil
-		} else if c == comma {
-			r.skipBlank()
-			if c, err := r.next(); c == rcurly || err != nil {
-				return nil, fmt.Errorf("object: unexpected ',' before '}'")
-			}
-			r.reset()
-		} else if isBlank(c) {
-			break
-		} else {
-			return nil, fmt.Errorf("object: unexpected character %c", c)
+		return false, err
+	}
+	if err := r.keyLimit(n); err != nil {
+		return false, err
+	}
+	member := r.member
+	notes := r.takeNotes()
+	keep := true
+	if r.keyFilter != nil {
+		key, keep = r.keyFilter(key)
+	}
+	if keep {
+		if err := r.store(obj, multi, keys, key, member, notes); err != nil {
+			return false, err
+		}
+	} else {
+		if err := r.skip(); err != nil {
+			return false, err
+		}
+		r.take()
+	}
+
+	c, err := r.next()
+	if err != nil {
+		return false, err
+	}
+	switch {
+	case c == rcurly:
+		return true, nil
+	case c == comma:
+		r.skipBlank()
+		if c, err := r.next(); err != nil {
+			return false, err
+		} else if c == rcurly && r.trailing {
+			return true, nil
+		} else if c == rcurly {
+			return false, fmt.Errorf("object: unexpected ',' before '}'")
+		}
+		r.reset()
+		return false, nil
+	case isBlank(c):
+		return true, r.closing(rcurly, "object")
+	default:
+		return false, fmt.Errorf("object: unexpected character %c", c)
+	}
+}
+
+func (r *Reader) store(obj Object, multi MultiObject, keys *[]string, key string, member Trivia, notes []string) error {
+	r.push(key)
+	val, err := r.read()
+	if err == nil && r.trivia != nil {
+		member.After = r.take()
+		r.setTrivia(func(t *Trivia) {
+			t.Before, t.Key, t.Colon, t.After = member.Before, member.Key, member.Colon, member.After
+			t.Comments = notes
+		})
+	}
+	r.pop()
+	if err != nil {
+		return err
+	}
+	if multi != nil {
+		if _, ok := multi[key]; !ok && r.trivia != nil {
+			*keys = append(*keys, key)
 		}
+		multi[key] = append(multi[key], val)
+		return nil
+	}
+	if _, ok := obj[key]; !ok && r.trivia != nil {
+		*keys = append(*keys, key)
 	}
+	return r.set(obj, key, val)
+}
+
+// skip consumes a value dropped by the key filter without decoding it: it
+// never reaches the value filter, the string sink or the spans, and is left
+// out of LastCount.
+func (r *Reader) skip() error {
+	count := r.count
+	err := r.scan(&visitor{})
+	r.count = count
+	return err
+}
+
+func (r *Reader) closing(end rune, what string) error {
 	r.skipBlank()
-	if c, _ := r.next(); c != rcurly {
-		return nil, fmt.Errorf("object: expected '}', got %c", c)
+	c, err := r.next()
+	if err != nil {
+		return err
 	}
-	return obj, nil
+	if c != end {
+		return fmt.Errorf("%s: expected '%c', got %c", what, end, c)
+	}
+	return nil
+}
+
+func (r *Reader) set(obj Object, key string, val Element) error {
+	if old, ok := obj[key]; ok && r.duplicate != nil {
+		var err error
+		if val, err = r.duplicate(key, old, val); err != nil {
+			return err
+		}
+	}
+	obj[key] = val
+	return nil
 }
 
 func (r *Reader) key() (string, error) {
 	defer r.buf.Reset()
 	r.skipBlank()
 
-	c, _ := r.next()
-	switch c {
-	case quote:
-	case rcurly:
+	var (
+		start  = r.offset
+		end    int64
+		c, err = r.next()
+	)
+	if err != nil {
+		return "", err
+	}
+	switch {
+	case r.quoted(c):
+		start++
+		err = r.chars(c, nil)
+		end = r.offset - int64(r.size)
+		r.keySpan = Span{Start: start - 1, End: r.offset}
+	case c == rcurly:
 		r.reset()
 		return "", errEmpty
+	case r.unquoted && isIdentStart(c):
+		r.reset()
+		err = r.name()
+		end = r.offset
+		r.keySpan = Span{Start: start, End: end}
 	default:
 		return "", fmt.Errorf("key: '\"' expected, got %c", c)
 	}
-	key, err := r.literal()
 	if err != nil {
 		return "", err
 	}
+	key := r.text(start, end, r.zeroCopy)
+	if r.normalizer != nil {
+		key = r.normalizer(key)
+	}
+	r.member = Trivia{
+		Before: r.take(),
+	}
 	r.skipBlank()
-	if c, _ = r.next(); c != colon {
+	r.member.Key = r.take()
+	if c, err = r.next(); err != nil {
+		return "", err
+	} else if c != colon {
 		return "", fmt.Errorf("object: ':' expected, got %c", c)
 	}
 	r.skipBlank()
-	if k, ok := key.(Literal[string]); ok {
-		return k.Literal, nil
+	r.member.Colon = r.take()
+	return key, nil
+}
+
+// text returns the content of buf, aliasing the source slice given to
+// NewBytes instead of copying when zero is set and the source bytes in
+// [start:end] are identical to the decoded text.
+func (r *Reader) text(start, end int64, zero bool) string {
+	if zero && r.src != nil && start >= 0 && end <= int64(len(r.src)) && start <= end {
+		b := r.src[start:end]
+		if bytes.Equal(b, r.buf.Bytes()) {
+			return unsafe.String(unsafe.SliceData(b), len(b))
+		}
+	}
+	return r.decoded()
+}
+
+// decoded returns the content of buf, carving it out of the scratch given to
+// ReadWith while it has room left.
+func (r *Reader) decoded() string {
+	n := r.buf.Len()
+	if n == 0 || len(r.arena)+n > cap(r.arena) {
+		return r.buf.String()
 	}
-	return "", fmt.Errorf("object: invalid key type")
+	off := len(r.arena)
+	r.arena = append(r.arena, r.buf.Bytes()...)
+	return unsafe.String(&r.arena[off], n)
 }
 
 func (r *Reader) array() (Element, error) {
-	r.enter()
+	err := r.enter()
 	defer r.leave()
+	if err != nil {
+		return nil, err
+	}
 
-	var arr Array
+	arr := r.newArray()
 	for {
-		r.skipBlank()
-		if c, _ := r.next(); c == rsquare {
-			return arr, nil
-		} else {
-			r.reset()
+		done, err := r.item(&arr)
+		if err != nil {
+			done, err = r.recover(err)
 		}
-		nod, err := r.read()
 		if err != nil {
 			return nil, err
 		}
-		arr = append(arr, nod)
-		c, err := r.next()
-		if err != nil {
+		if done {
+			break
+		}
+	}
+	r.layout(nil)
+	return arr, nil
+}
+
+func (r *Reader) item(arr *Array) (bool, error) {
+	r.skipBlank()
+	if c, _ := r.next(); c == rsquare {
+		return true, nil
+	}
+	r.reset()
+	if err := r.arrayLimit(len(*arr) + 1); err != nil {
+		return false, err
+	}
+	before := r.take()
+	notes := r.takeNotes()
+	if r.tracking() {
+		r.push(strconv.Itoa(len(*arr)))
+	}
+	nod, err := r.read()
+	if err == nil && r.trivia != nil {
+		after := r.take()
+		r.setTrivia(func(t *Trivia) {
+			t.Before, t.After, t.Comments = before, after, notes
+		})
+	}
+	r.pop()
+	if err != nil {
+		return false, err
+	}
+	*arr = append(*arr, nod)
+	c, err := r.next()
+	if err != nil {
+		return false, err
+	}
+	switch {
+	case c == rsquare:
+		return true, nil
+	case c == comma:
+		r.skipBlank()
+		if c, err := r.next(); err != nil {
+			return false, err
+		} else if c == rsquare && r.trailing {
+			return true, nil
+		} else if c == rsquare {
+			return false, fmt.Errorf("array: unexpected ',' before ']'")
+		}
+		r.reset()
+		return false, nil
+	case isBlank(c):
+		return true, r.closing(rsquare, "array")
+	default:
+		return false, fmt.Errorf("array: unexpected character %c", c)
+	}
+}
+
+func (r *Reader) number() (Element, error) {
+	c, err := r.next()
+	if err != nil {
+		return nil, err
+	}
+	if isMinus(c) {
+		r.buf.WriteRune(c)
+		if c, err = r.next(); err != nil {
+			return nil, truncated(err)
+		}
+		if !isDigit(c) {
+			return nil, fmt.Errorf("number: expected digit after '-', got %c", c)
+		}
+	}
+	var last rune
+	if c == '0' {
+		r.buf.WriteRune(c)
+		if last, err = r.next(); err != nil {
+			if !errors.Is(err, io.EOF) {
+				return nil, err
+			}
+			last = eof
+		}
+		if last != eof && last != dot && last != 'e' && last != 'E' && !isDelimiter(last) {
+			return nil, fmt.Errorf("unexpected character after 0, %c", last)
+		}
+	} else {
+		r.reset()
+		if last, err = r.digits(); err != nil {
 			return nil, err
 		}
-		if c == rsquare {
-			return arr, nil
-		} else if c == comma {
-			r.skipBlank()
-			if c, err := r.next(); c == rsquare || err != nil {
-				return nil, fmt.Errorf("array: unexpected ',' before ']'")
+	}
+	var fraction, exponent bool
+	if last == dot {
+		fraction = true
+		if last, err = r.fraction(); err != nil {
+			return nil, err
+		}
+	}
+	if last == 'e' || last == 'E' {
+		exponent = true
+		if last, err = r.exponent(last); err != nil {
+			return nil, err
+		}
+	}
+	if last == eof && r.resumable {
+		return nil, truncated(io.EOF)
+	}
+	if last != eof {
+		r.reset()
+	}
+	return r.makeNumber(fraction, exponent)
+}
+
+func (r *Reader) makeNumber(fraction, exponent bool) (Element, error) {
+	if r.numberParser != nil {
+		return r.numberParser(r.decoded())
+	}
+	str := r.decoded()
+	if r.integers && !fraction && !exponent {
+		if n, err := Integer(str); err == nil {
+			return n, nil
+		}
+	}
+	return Number(str)
+}
+
+func (r *Reader) digit(c rune) error {
+	r.buf.WriteRune(c)
+	if r.maxNumber > 0 && r.buf.Len() > r.maxNumber {
+		return fmt.Errorf("number: maximum length exceeded (%d)", r.maxNumber)
+	}
+	return nil
+}
+
+func (r *Reader) digits() (rune, error) {
+	for {
+		c, err := r.next()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return eof, nil
 			}
-			r.reset()
-		} else if isBlank(c) {
-			break
-		} else {
-			return nil, fmt.Errorf("array: unexpected character %c", c)
+			return 0, err
+		}
+		if !isDigit(c) {
+			return c, nil
+		}
+		if err := r.digit(c); err != nil {
+			return 0, err
 		}
 	}
-	r.skipBlank()
-	if c, _ := r.next(); c != rsquare {
-		return nil, fmt.Errorf("array: expected ']', got %c", c)
+}
+
+func (r *Reader) fraction() (rune, error) {
+	r.buf.WriteRune(dot)
+	c, err := r.next()
+	if err != nil {
+		return 0, truncated(err)
 	}
-	return arr, nil
+	if !isDigit(c) {
+		return 0, fmt.Errorf("number: expected digit after '.', got %c", c)
+	}
+	r.reset()
+	return r.digits()
 }
 
-func (r *Reader) number() (Element, error) {
-	c, _ := r.next()
-	if isSign(c) {
-		r.buf.WriteRune(c)
-		c, _ = r.next()
+func (r *Reader) exponent(exp rune) (rune, error) {
+	r.buf.WriteRune(exp)
+	c, err := r.next()
+	if err != nil {
+		return 0, truncated(err)
 	}
-	if c == '0' {
+	if isSign(c) {
 		r.buf.WriteRune(c)
-		c, _ = r.next()
-		if c == dot {
-			err := r.fraction()
-			if err != nil {
-				return nil, err
-			}
-		} else if isDelimiter(c) {
-			r.reset()
-		} else {
-			return nil, fmt.Errorf("unexpected character after 0, %c", c)
+		if c, err = r.next(); err != nil {
+			return 0, truncated(err)
 		}
-		return Number(r.buf.String())
+	}
+	if !isDigit(c) {
+		return 0, fmt.Errorf("number: unexpected character after exponent: %c", c)
 	}
 	r.reset()
+	return r.digits()
+}
 
-	var last rune
+// truncated reports an end of input found in the middle of a number, which
+// must not be mistaken for the clean end of the stream.
+func truncated(err error) error {
+	if errors.Is(err, io.EOF) {
+		return fmt.Errorf("number: unexpected end of input: %w", io.ErrUnexpectedEOF)
+	}
+	return err
+}
+
+func (r *Reader) name() error {
 	for {
 		c, err := r.next()
 		if err != nil {
 			if errors.Is(err, io.EOF) {
-				last = utf8.RuneError
-				break
+				return nil
 			}
-			return nil, err
+			return err
 		}
-		if !isDigit(c) {
-			last = c
-			break
+		if !isIdentPart(c) {
+			r.reset()
+			return nil
 		}
 		r.buf.WriteRune(c)
 	}
-	var err error
-	switch last {
-	case utf8.RuneError:
-	case dot:
-		err = r.fraction()
-	case 'e', 'E':
-		err = r.exponent(last)
-	default:
-		r.reset()
-	}
-	if err != nil {
+}
+
+const sinkChunk = 32 << 10
+
+func (r *Reader) literalTo(delim rune, w io.Writer) (Element, error) {
+	start := r.offset
+	if err := r.chars(delim, w); err != nil {
 		return nil, err
 	}
-	return Number(r.buf.String())
+	if w == nil {
+		return String(r.text(start, r.offset-int64(r.size), r.zeroStrings)), nil
+	}
+	return String(""), nil
 }
 
-func (r *Reader) fraction() error {
-	defer r.reset()
-	r.buf.WriteRune(dot)
+func (r *Reader) chars(delim rune, w io.Writer) error {
 	for {
 		c, err := r.next()
 		if err != nil {
-			if errors.Is(err, io.EOF) {
-				break
-			}
+			r.quote = delim
 			return err
 		}
-		if !isDigit(c) {
+		if c == backslash {
+			if err := r.escape(delim); err != nil {
+				r.quote = delim
+				return err
+			}
+		} else if c == delim {
 			break
+		} else if c < 0x20 {
+			r.quote = delim
+			return fmt.Errorf("string: unescaped control character %U", c)
+		} else {
+			r.buf.WriteRune(c)
+		}
+		if w != nil && r.buf.Len() >= sinkChunk {
+			if _, err := r.buf.WriteTo(w); err != nil {
+				return err
+			}
 		}
-		r.buf.WriteRune(c)
+	}
+	if w != nil {
+		_, err := r.buf.WriteTo(w)
+		return err
 	}
 	return nil
 }
 
-func (r *Reader) exponent(exp rune) error {
-	r.buf.WriteRune(exp)
-	c, _ := r.next()
-	switch {
-	case isSign(c):
-		r.buf.WriteRune(c)
-	case isDigit(c):
-		r.reset()
-	default:
-		return fmt.Errorf("number: unexpected character after exponent: %c", c)
+func (r *Reader) escape(delim rune) error {
+	c, err := r.next()
+	if err != nil {
+		return err
 	}
-	defer r.reset()
-	for {
-		c, err := r.next()
+	return r.unescape(c, delim)
+}
+
+func (r *Reader) unescape(c, delim rune) error {
+	switch c {
+	case 'b':
+		r.buf.WriteByte('\b')
+	case 'f':
+		r.buf.WriteByte('\f')
+	case 'n':
+		r.buf.WriteByte('\n')
+	case 'r':
+		r.buf.WriteByte('\r')
+	case 't':
+		r.buf.WriteByte('\t')
+	case '/', quote, backslash:
+		r.buf.WriteRune(c)
+	case 'u':
+		u, err := r.hex()
 		if err != nil {
-			if errors.Is(err, io.EOF) {
-				break
-			}
 			return err
 		}
-		if !isDigit(c) {
-			break
+		return r.codepoint(u, delim)
+	case squote:
+		if !r.single {
+			return r.escapeError(fmt.Sprintf("unknown escape \\%c", c), r.offset-int64(r.size)-1)
 		}
 		r.buf.WriteRune(c)
+	default:
+		return r.escapeError(fmt.Sprintf("unknown escape \\%c", c), r.offset-int64(r.size)-1)
 	}
 	return nil
 }
 
-func (r *Reader) literal() (Element, error) {
-	for {
+func (r *Reader) codepoint(u, delim rune) error {
+	if !utf16.IsSurrogate(u) {
+		r.buf.WriteRune(u)
+		return nil
+	}
+	if u >= 0xDC00 {
+		r.buf.WriteRune(utf8.RuneError)
+		return nil
+	}
+	c, err := r.next()
+	if err != nil {
+		return err
+	}
+	if c == delim {
+		err := r.escapeError("incomplete surrogate pair at end of string", r.offset-int64(r.size)-6)
+		r.reset()
+		return err
+	}
+	if c != backslash {
+		r.buf.WriteRune(utf8.RuneError)
+		r.reset()
+		return nil
+	}
+	if c, err = r.next(); err != nil {
+		return err
+	}
+	if c != 'u' {
+		r.buf.WriteRune(utf8.RuneError)
+		return r.unescape(c, delim)
+	}
+	v, err := r.hex()
+	if err != nil {
+		return err
+	}
+	if c := utf16.DecodeRune(u, v); c != utf8.RuneError {
+		r.buf.WriteRune(c)
+		return nil
+	}
+	r.buf.WriteRune(utf8.RuneError)
+	return r.codepoint(v, delim)
+}
+
+func (r *Reader) hex() (rune, error) {
+	var u rune
+	for i := 0; i < 4; i++ {
 		c, err := r.next()
 		if err != nil {
-			return nil, err
-		}
-		if c == backslash {
-			if err := r.escape(); err != nil {
-				return nil, err
-			}
-			continue
+			return 0, err
 		}
-		if c == quote {
-			break
+		if !isHex(c) {
+			return 0, r.escapeError(fmt.Sprintf("%c not a hex character", c), r.offset-int64(r.size))
 		}
-		r.buf.WriteRune(c)
+		u = u<<4 | unhex(c)
 	}
-	return String(r.buf.String()), nil
+	return u, nil
 }
 
-func (r *Reader) escape() error {
-	r.buf.WriteRune(backslash)
-	c, _ := r.next()
-	switch c {
-	case 'b', 'f', 'n', 'r', 't', '/', quote, backslash:
-		r.buf.WriteRune(c)
-	case 'u':
-		r.buf.WriteRune(c)
-		for i := 0; i < 4; i++ {
-			c, _ = r.next()
-			if !isHex(c) {
-				return fmt.Errorf("%c not a hex character", c)
-			}
-			r.buf.WriteRune(c)
+func (r *Reader) escapeError(msg string, offset int64) error {
+	const context = 32
+
+	str := r.buf.String()
+	if len(str) > context {
+		n := len(str) - context
+		for n < len(str) && !utf8.RuneStart(str[n]) {
+			n++
 		}
-	default:
-		return fmt.Errorf("unknown escape")
+		str = "..." + str[n:]
 	}
-	return nil
+	return fmt.Errorf("string: %s at offset %d (after %q)", msg, offset, str)
 }
 
 func (r *Reader) identifier() (Element, error) {
@@ -399,7 +1863,7 @@ func (r *Reader) identifier() (Element, error) {
 	for {
 		c, err := r.next()
 		if err != nil {
-			if errors.Is(err, io.EOF) {
+			if errors.Is(err, io.EOF) && !r.resumable {
 				break
 			}
 			return nil, err
@@ -420,32 +1884,399 @@ func (r *Reader) identifier() (Element, error) {
 }
 
 func (r *Reader) next() (rune, error) {
-	c, _, err := r.rs.ReadRune()
+	if !r.checked {
+		r.checked = true
+		r.err = r.detect()
+	}
+	if r.err != nil {
+		r.size = 0
+		return 0, r.err
+	}
+	if r.pending {
+		r.pending = false
+		r.size = r.width
+		r.offset += int64(r.size)
+		if r.capturing {
+			r.rawbuf.WriteRune(r.last)
+		}
+		return r.last, nil
+	}
+	c, n, err := r.rs.ReadRune()
+	r.last = c
+	r.size = n
+	r.offset += int64(n)
+	if r.resumable {
+		if err == nil {
+			r.journal.WriteRune(c)
+		} else if errors.Is(err, io.EOF) {
+			r.eof = true
+		}
+	}
+	if r.progress != nil && r.offset >= r.mark {
+		r.mark = r.offset - r.offset%r.every + r.every
+		r.progress(r.offset)
+	}
+	if c == utf8.RuneError && n == 1 && r.invalid == PolicyError {
+		r.size = 0
+		r.err = fmt.Errorf("invalid UTF-8 sequence at offset %d", r.offset-1)
+		return c, r.err
+	}
+	if err == nil && r.measuring && r.offset-r.valueStart > int64(r.maxValue) {
+		r.overflow, r.overDepth = true, r.depth
+		r.err = limitError{msg: fmt.Sprintf("value size limit exceeded (%d bytes)", r.maxValue)}
+		return c, r.err
+	}
+	if err == nil && r.capturing {
+		r.rawbuf.WriteRune(c)
+	}
 	return c, err
 }
 
+// detect skips the UTF-8 byte order mark and rejects the UTF-16 and UTF-32
+// encodings, recognized by their byte order mark or by the zero bytes around
+// the first character as described in RFC 4627.
+func (r *Reader) detect() error {
+	b, err := r.rs.Peek(4)
+	switch {
+	case err != nil && !errors.Is(err, io.EOF):
+		return err
+	case bytes.HasPrefix(b, []byte{0xEF, 0xBB, 0xBF}):
+		n, _ := r.rs.Discard(3)
+		r.offset += int64(n)
+		return nil
+	case bytes.HasPrefix(b, []byte{0, 0, 0xFE, 0xFF}):
+		return fmt.Errorf("unsupported encoding: UTF-32BE")
+	case bytes.HasPrefix(b, []byte{0xFF, 0xFE, 0, 0}):
+		return fmt.Errorf("unsupported encoding: UTF-32LE")
+	case bytes.HasPrefix(b, []byte{0xFE, 0xFF}):
+		return fmt.Errorf("unsupported encoding: UTF-16BE")
+	case bytes.HasPrefix(b, []byte{0xFF, 0xFE}):
+		return fmt.Errorf("unsupported encoding: UTF-16LE")
+	case len(b) == 4 && b[0] == 0 && b[1] == 0 && b[2] == 0 && b[3] != 0:
+		return fmt.Errorf("unsupported encoding: UTF-32BE")
+	case len(b) == 4 && b[0] != 0 && b[1] == 0 && b[2] == 0 && b[3] == 0:
+		return fmt.Errorf("unsupported encoding: UTF-32LE")
+	case len(b) >= 2 && b[0] == 0 && b[1] != 0:
+		return fmt.Errorf("unsupported encoding: UTF-16BE")
+	case len(b) >= 2 && b[0] != 0 && b[1] == 0:
+		return fmt.Errorf("unsupported encoding: UTF-16LE")
+	default:
+		return nil
+	}
+}
+
+// reset pushes back the last rune returned by next. Only one rune can be
+// pushed back: calling reset twice in a row or after a failed next is a no-op.
 func (r *Reader) reset() {
-	r.rs.UnreadRune()
+	if r.size == 0 {
+		return
+	}
+	r.pending = true
+	r.width = r.size
+	r.offset -= int64(r.size)
+	r.size = 0
+	if r.capturing {
+		r.rawbuf.Truncate(r.rawbuf.Len() - utf8.RuneLen(r.last))
+	}
 }
 
 func (r *Reader) skipBlank() {
-	defer r.reset()
+	if r.strict && r.depth == 0 {
+		return
+	}
 	for {
-		c, _ := r.next()
+		mark := r.rawbuf.Len()
+		c, err := r.next()
+		if err != nil {
+			if !errors.Is(err, io.EOF) {
+				r.err = err
+			}
+			return
+		}
+		if c == slash && r.comments {
+			var note *bytes.Buffer
+			if r.keepComments && r.trivia != nil {
+				note = &r.note
+				note.WriteRune(c)
+			}
+			if err := r.skipComment(note); err != nil {
+				r.err = err
+				return
+			}
+			r.uncapture(mark)
+			if note != nil {
+				r.keepNote()
+			}
+			continue
+		}
 		if !isBlank(c) {
-			break
+			r.reset()
+			return
+		}
+		r.uncapture(mark)
+		if r.trivia != nil {
+			r.ws.WriteRune(c)
+		}
+	}
+}
+
+func (r *Reader) separator() error {
+	if !r.commas || !r.more {
+		return nil
+	}
+	c, err := r.next()
+	if err != nil || c != comma {
+		r.reset()
+		return nil
+	}
+	r.more = false
+	r.skipBlank()
+	if _, err := r.next(); errors.Is(err, io.EOF) && !r.trailing {
+		return fmt.Errorf("read: expected value after ','")
+	}
+	r.reset()
+	return nil
+}
+
+func (r *Reader) blank(where string) error {
+	c, err := r.next()
+	if err != nil {
+		return nil
+	}
+	r.reset()
+	if isBlank(c) {
+		return fmt.Errorf("read: %s whitespace not allowed", where)
+	}
+	return nil
+}
+
+func (r *Reader) suspend(err error) error {
+	if !r.eof || len(bytes.TrimSpace(r.journal.Bytes())) == 0 {
+		return err
+	}
+	saved := make([]byte, r.journal.Len())
+	copy(saved, r.journal.Bytes())
+	r.rs.Reset(&prefixReader{
+		prefix: saved,
+		rs:     r.in,
+	})
+	r.offset -= int64(len(saved))
+	if errors.Is(r.err, io.EOF) {
+		r.err = nil
+	}
+	r.pending = false
+	r.size = 0
+	r.depth = 0
+	r.path = r.path[:0]
+	r.buf.Reset()
+	r.rawbuf.Reset()
+	r.capturing = false
+	return io.ErrUnexpectedEOF
+}
+
+type prefixReader struct {
+	prefix []byte
+	rs     io.Reader
+}
+
+func (p *prefixReader) Read(b []byte) (int, error) {
+	if len(p.prefix) == 0 {
+		return p.rs.Read(b)
+	}
+	n := copy(b, p.prefix)
+	p.prefix = p.prefix[n:]
+	return n, nil
+}
+
+func (r *Reader) take() string {
+	if r.trivia == nil {
+		return ""
+	}
+	defer r.ws.Reset()
+	return r.ws.String()
+}
+
+func (r *Reader) keepNote() {
+	defer r.note.Reset()
+	r.ws.Write(r.note.Bytes())
+	r.notes = append(r.notes, strings.TrimRight(r.note.String(), "\r\n"))
+}
+
+func (r *Reader) takeNotes() []string {
+	defer func() {
+		r.notes = nil
+	}()
+	return r.notes
+}
+
+func (r *Reader) layout(keys []string) {
+	if r.trivia == nil {
+		return
+	}
+	r.takeNotes()
+	ws := r.take()
+	r.setTrivia(func(t *Trivia) {
+		t.Close, t.Keys = ws, keys
+	})
+}
+
+func (r *Reader) setTrivia(fn func(*Trivia)) {
+	ptr := joinPointer(r.path)
+	t := r.trivia[ptr]
+	fn(&t)
+	r.trivia[ptr] = t
+}
+
+func (r *Reader) skipComment(w *bytes.Buffer) error {
+	c, err := r.next()
+	if err != nil {
+		if errors.Is(err, io.EOF) && !r.resumable {
+			return fmt.Errorf("comment: unexpected end of input after '/'")
 		}
+		return err
 	}
+	if w != nil {
+		w.WriteRune(c)
+	}
+	switch c {
+	case slash:
+		for {
+			c, err := r.next()
+			if err != nil {
+				if errors.Is(err, io.EOF) {
+					return nil
+				}
+				return err
+			}
+			if w != nil {
+				w.WriteRune(c)
+			}
+			if isNL(c) {
+				return nil
+			}
+		}
+	case star:
+		var prev rune
+		for {
+			c, err := r.next()
+			if err != nil {
+				if errors.Is(err, io.EOF) && !r.resumable {
+					return fmt.Errorf("comment: unterminated comment")
+				}
+				return err
+			}
+			if w != nil {
+				w.WriteRune(c)
+			}
+			if prev == star && c == slash {
+				return nil
+			}
+			prev = c
+		}
+	default:
+		return fmt.Errorf("comment: unexpected character %c after '/'", c)
+	}
+}
+
+func (r *Reader) quoted(c rune) bool {
+	return isString(c) || (c == squote && r.single)
+}
+
+func (r *Reader) capture(c rune) {
+	r.capturing = true
+	r.rawbuf.Reset()
+	r.rawbuf.WriteRune(c)
+}
+
+func (r *Reader) uncapture(mark int) {
+	if r.capturing && mark <= r.rawbuf.Len() {
+		r.rawbuf.Truncate(mark)
+	}
+}
+
+func (r *Reader) captured(el Element) Element {
+	r.capturing = false
+	if i, ok := el.(interface{ withRaw(string) Element }); ok {
+		return i.withRaw(r.rawbuf.String())
+	}
+	return el
+}
+
+func (r *Reader) sink() io.Writer {
+	if r.stringSink == nil {
+		return nil
+	}
+	var key string
+	if n := len(r.path); n > 0 {
+		key = r.path[n-1]
+	}
+	return r.stringSink(key)
+}
+
+func (r *Reader) tracking() bool {
+	return r.spans != nil || r.trivia != nil || r.valueFilter != nil || r.stringSink != nil
+}
+
+func (r *Reader) push(key string) {
+	if r.tracking() {
+		r.path = append(r.path, key)
+	}
+}
+
+func (r *Reader) pop() {
+	if n := len(r.path); n > 0 && r.tracking() {
+		r.path = r.path[:n-1]
+	}
+}
+
+func (r *Reader) incr() error {
+	r.count++
+	if r.maxElements > 0 && r.count > r.maxElements {
+		return limitError{msg: fmt.Sprintf("element count limit exceeded (%d)", r.maxElements)}
+	}
+	return nil
+}
+
+func (r *Reader) unexpected(c rune) error {
+	if r.depth == 0 {
+		switch c {
+		case rcurly:
+			return fmt.Errorf("read: unexpected '}' with no open object")
+		case rsquare:
+			return fmt.Errorf("read: unexpected ']' with no open array")
+		}
+	}
+	return fmt.Errorf("read: unexpected character %c", c)
+}
+
+func (r *Reader) keyLimit(n int) error {
+	if r.maxKeys > 0 && n > r.maxKeys {
+		return limitError{msg: fmt.Sprintf("object: maximum number of keys exceeded (%d)", r.maxKeys)}
+	}
+	return nil
 }
 
-func (r *Reader) enter() {
+func (r *Reader) arrayLimit(n int) error {
+	if r.maxArray > 0 && n > r.maxArray {
+		return limitError{msg: fmt.Sprintf("array: maximum length exceeded (%d)", r.maxArray)}
+	}
+	return nil
+}
+
+func (r *Reader) enter() error {
 	r.depth++
+	if r.maxDepth > 0 && r.depth > r.maxDepth {
+		return limitError{msg: fmt.Sprintf("maximum depth exceeded (%d)", r.maxDepth)}
+	}
+	return nil
 }
 
 func (r *Reader) leave() {
 	r.depth--
 }
 
+const eof = -1
+
 const (
 	kwNull  = "null"
 	kwTrue  = "true"
@@ -461,6 +2292,7 @@ const (
 	nl        = '\n'
 	cr        = '\r'
 	quote     = '"'
+	squote    = '\''
 	dot       = '.'
 	colon     = ':'
 	space     = ' '
@@ -468,10 +2300,12 @@ const (
 	minus     = '-'
 	plus      = '+'
 	backslash = '\\'
+	slash     = '/'
+	star      = '*'
 )
 
 func isDelimiter(r rune) bool {
-	return isBlank(r) || r == comma || r == rsquare || r == rcurly
+	return isBlank(r) || r == comma || r == rsquare || r == rcurly || r == slash
 }
 
 func isNL(r rune) bool {
@@ -517,3 +2351,22 @@ func isIdent(r rune) bool {
 func isHex(r rune) bool {
 	return (r >= '0' && r <= '9') || (r >= 'a' && r <= 'f') || (r >= 'A' && r <= 'F')
 }
+
+func unhex(r rune) rune {
+	switch {
+	case r >= 'a':
+		return r - 'a' + 10
+	case r >= 'A':
+		return r - 'A' + 10
+	default:
+		return r - '0'
+	}
+}
+
+func isIdentStart(r rune) bool {
+	return r == '_' || r == '$' || unicode.IsLetter(r)
+}
+
+func isIdentPart(r rune) bool {
+	return isIdentStart(r) || unicode.IsDigit(r)
+}
@@ -1,10 +1,22 @@
 package saj
 
 import (
+	"bytes"
+	"context"
+	"encoding"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
+	"math"
+	"math/big"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
 	"strings"
 	"testing"
+	"testing/iotest"
 )
 
 func TestReader_Error(t *testing.T) {
@@ -22,12 +34,119 @@ func TestReader_Error(t *testing.T) {
 		`{"name": }`,
 		`{"unclosed": "object"`,
 		`{true: false}`,
+		`+5`,
+		`[+5]`,
+		`-`,
+		`-a`,
+		`01`,
+		`-01`,
+		`1.`,
+		`1.e5`,
+		`[1.]`,
+		`1e`,
+		`1e+`,
+		`[1E-]`,
+		`0x10`,
+		`}`,
+		`]`,
+		` ]`,
+		`{"a": }`,
+		`[}]`,
 	}
 	for _, d := range data {
-		r := New(strings.NewReader(d))
-		e, err := r.Read()
-		if err == nil {
-			t.Errorf("%s: invalid json parsed properly as %v", d, e)
+		for name, rs := range readers(d) {
+			r := New(rs)
+			e, err := r.Read()
+			if err == nil {
+				t.Errorf("%s(%s): invalid json parsed properly as %v", d, name, e)
+			}
+		}
+	}
+}
+
+func TestReader_TruncatedNumber(t *testing.T) {
+	for _, d := range []string{`-`, `1.`, `1e`, `1E-`, `1e+`, `[-`, `{"a": 1.`} {
+		e, err := New(strings.NewReader(d)).Read()
+		if err == nil || errors.Is(err, io.EOF) {
+			t.Errorf("%s: expected syntax error, got %v (%v)", d, err, e)
+		}
+	}
+}
+
+func TestReader_Truncated(t *testing.T) {
+	data := []string{
+		`[1,2`,
+		`[1,`,
+		`[`,
+		`{"a":1`,
+		`{"a":1,`,
+		`{"a"`,
+		`{"a":`,
+		`{`,
+		`"abc`,
+		`[1, 2 `,
+		`{"a": 1 `,
+	}
+	for _, d := range data {
+		for name, read := range map[string]func(*Reader) (Element, error){
+			"read":      (*Reader).Read,
+			"iterative": (*Reader).ReadIterative,
+		} {
+			e, err := read(New(strings.NewReader(d)))
+			if !errors.Is(err, io.ErrUnexpectedEOF) {
+				t.Errorf("%s %q: want %s, got %v (%v)", name, d, io.ErrUnexpectedEOF, err, e)
+			}
+		}
+	}
+}
+
+func TestReader_Encoding(t *testing.T) {
+	data := []struct {
+		Input string
+		Want  string
+	}{
+		{Input: "\xFE\xFF\x00[\x00]", Want: "UTF-16BE"},
+		{Input: "\xFF\xFE[\x00]\x00", Want: "UTF-16LE"},
+		{Input: "\x00[\x00]", Want: "UTF-16BE"},
+		{Input: "[\x001\x00]\x00", Want: "UTF-16LE"},
+		{Input: "\x00\x00\xFE\xFF\x00\x00\x00[", Want: "UTF-32BE"},
+		{Input: "\xFF\xFE\x00\x00[\x00\x00\x00", Want: "UTF-32LE"},
+		{Input: "\x00\x00\x001", Want: "UTF-32BE"},
+		{Input: "1\x00\x00\x00", Want: "UTF-32LE"},
+	}
+	for _, d := range data {
+		for name, rs := range readers(d.Input) {
+			_, err := New(rs).Read()
+			if want := "unsupported encoding: " + d.Want; err == nil || err.Error() != want {
+				t.Errorf("%q(%s): want %q, got %v", d.Input, name, want, err)
+			}
+		}
+	}
+	r := NewWithOptions(strings.NewReader("\xEF\xBB\xBF{\"a\": [1]}"), WithSpans())
+	if _, err := r.Read(); err != nil {
+		t.Fatalf("utf-8 bom: unexpected error: %s", err)
+	}
+	if span := r.Spans()[""]; span.Start != 3 {
+		t.Errorf("utf-8 bom: want value starting at offset 3, got %d", span.Start)
+	}
+	if err := New(strings.NewReader("\xFF\xFE[\x00]\x00")).Skip(); err == nil {
+		t.Errorf("skip: utf-16 input accepted")
+	}
+}
+
+func TestReader_UnbalancedClose(t *testing.T) {
+	data := []struct {
+		Input string
+		Want  string
+	}{
+		{Input: `}`, Want: "read: unexpected '}' with no open object"},
+		{Input: ` ]`, Want: "read: unexpected ']' with no open array"},
+		{Input: `{"a": }`, Want: "read: unexpected character }"},
+	}
+	for _, d := range data {
+		_, err := New(strings.NewReader(d.Input)).Read()
+		if err == nil || err.Error() != d.Want {
+			t.Errorf("%s: want %q, got %v", d.Input, d.Want, err)
 		}
 	}
 }
@@ -65,6 +184,14 @@ func TestReader(t *testing.T) {
 			Input: `42`,
 			Type:  TypeNumber,
 		},
+		{
+			Input: `0`,
+			Type:  TypeNumber,
+		},
+		{
+			Input: `-0`,
+			Type:  TypeNumber,
+		},
 		{
 			Input: `true`,
 			Type:  TypeBool,
@@ -111,19 +238,1849 @@ func TestReader(t *testing.T) {
 		},
 	}
 	for _, d := range data {
-		r := New(strings.NewReader(d.Input))
+		for name, rs := range readers(d.Input) {
+			r := New(rs)
+			e, err := r.Read()
+			if err != nil && !errors.Is(err, io.EOF) {
+				t.Errorf("%s(%s): unexpected error: %s", d.Input, name, err)
+				continue
+			}
+			if e == nil {
+				t.Errorf("%s(%s): nil element received (%s)", d.Input, name, err)
+				continue
+			}
+			if e.Type() != d.Type {
+				t.Errorf("%s(%s): unexpected element type", d.Input, name)
+				continue
+			}
+		}
+	}
+}
+
+func readers(str string) map[string]io.Reader {
+	return map[string]io.Reader{
+		"string":   strings.NewReader(str),
+		"onebyte":  iotest.OneByteReader(strings.NewReader(str)),
+		"half":     iotest.HalfReader(strings.NewReader(str)),
+		"dataerr":  iotest.DataErrReader(strings.NewReader(str)),
+		"stutter":  &stutterReader{r: strings.NewReader(str)},
+		"multiple": multiReader(str),
+	}
+}
+
+func multiReader(str string) io.Reader {
+	var rs []io.Reader
+	for i := 0; i < len(str); i++ {
+		rs = append(rs, strings.NewReader(str[i:i+1]))
+	}
+	return io.MultiReader(rs...)
+}
+
+type stutterReader struct {
+	r     io.Reader
+	empty bool
+}
+
+func (s *stutterReader) Read(b []byte) (int, error) {
+	s.empty = !s.empty
+	if s.empty {
+		return 0, nil
+	}
+	return s.r.Read(b)
+}
+
+func TestReader_ReadError(t *testing.T) {
+	errBroken := errors.New("broken")
+	data := []string{
+		`-`,
+		`0`,
+		`-0`,
+		`12e`,
+		`12e-`,
+	}
+	for _, d := range data {
+		rs := io.MultiReader(strings.NewReader(d), iotest.ErrReader(errBroken))
+		e, err := New(rs).Read()
+		if !errors.Is(err, errBroken) {
+			t.Errorf("%s: expected read error, got %v (%v)", d, err, e)
+		}
+	}
+}
+
+type failOnceReader struct {
+	chunks []string
+	err    error
+}
+
+func (f *failOnceReader) Read(b []byte) (int, error) {
+	if len(f.chunks) == 0 {
+		return 0, io.EOF
+	}
+	str := f.chunks[0]
+	f.chunks = f.chunks[1:]
+	if str == "" {
+		return 0, f.err
+	}
+	return copy(b, str), nil
+}
+
+func TestReader_TransientError(t *testing.T) {
+	errBroken := errors.New("broken")
+	data := [][]string{
+		{"[1, ", "", "2]"},
+		{`{"a": `, "", "1}"},
+		{"[1 ", "", ", 2]"},
+		{" ", "", "[]"},
+		{"[10000     ", "", ", 2]"},
+		{`{"name": "foo"   `, "", "}"},
+	}
+	for _, d := range data {
+		for name, read := range map[string]func(*Reader) (Element, error){
+			"read":      (*Reader).Read,
+			"iterative": (*Reader).ReadIterative,
+		} {
+			rs := &failOnceReader{chunks: append([]string{}, d...), err: errBroken}
+			e, err := read(New(rs))
+			if !errors.Is(err, errBroken) {
+				t.Errorf("%s %q: expected read error, got %v (%v)", name, d, err, e)
+			}
+		}
+	}
+}
+
+func TestReader_EOF(t *testing.T) {
+	data := []string{
+		"",
+		"   ",
+		"\n\t\r\n  ",
+	}
+	for _, d := range data {
+		for _, rs := range readers(d) {
+			e, err := New(rs).Read()
+			if !errors.Is(err, io.EOF) {
+				t.Errorf("%q: expected io.EOF, got %v (%v)", d, err, e)
+			}
+		}
+	}
+	comments := []string{
+		"// comment only",
+		"  /* block */  \n",
+		"/* one */ // two\n",
+	}
+	for _, d := range comments {
+		e, err := NewWithOptions(strings.NewReader(d), WithComments()).Read()
+		if !errors.Is(err, io.EOF) {
+			t.Errorf("%q: expected io.EOF, got %v (%v)", d, err, e)
+		}
+		e, err = NewWithOptions(strings.NewReader(d), WithComments(), WithEmptyAsNull()).Read()
+		if err != nil || !IsNull(e) {
+			t.Errorf("%q: expected null, got %v (%v)", d, e, err)
+		}
+	}
+	if _, err := NewWithOptions(strings.NewReader("/* unterminated"), WithComments()).Read(); err == nil || errors.Is(err, io.EOF) {
+		t.Errorf("unterminated comment: expected syntax error, got %v", err)
+	}
+	r := New(strings.NewReader(`"foobar"   `))
+	if _, err := r.Read(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if _, err := r.Read(); !errors.Is(err, io.EOF) {
+		t.Errorf("expected io.EOF after last value, got %v", err)
+	}
+}
+
+func TestReader_Next(t *testing.T) {
+	data := []struct {
+		Input   string
+		Count   int
+		Err     error
+		Invalid bool
+	}{
+		{Input: ``, Count: 0},
+		{Input: `1 "two" [3] {"four": 4}  `, Count: 4},
+		{Input: `1 [2`, Count: 1, Err: io.ErrUnexpectedEOF},
+		{Input: `1 "two`, Count: 1, Err: io.ErrUnexpectedEOF},
+		{Input: `1 tru 3`, Count: 1, Invalid: true},
+	}
+	for _, d := range data {
+		var (
+			r = New(strings.NewReader(d.Input))
+			n int
+		)
+		for r.Next() {
+			if r.Value() == nil {
+				t.Errorf("%q: nil value at %d", d.Input, n)
+			}
+			n++
+		}
+		if n != d.Count {
+			t.Errorf("%q: want %d values, got %d", d.Input, d.Count, n)
+		}
+		switch err := r.Err(); {
+		case d.Err != nil:
+			if !errors.Is(err, d.Err) {
+				t.Errorf("%q: want %v, got %v", d.Input, d.Err, err)
+			}
+		case d.Invalid:
+			if err == nil {
+				t.Errorf("%q: expected syntax error", d.Input)
+			}
+		case err != nil:
+			t.Errorf("%q: unexpected error: %s", d.Input, err)
+		}
+		if r.Next() || r.Value() != nil {
+			t.Errorf("%q: next should keep failing once stopped", d.Input)
+		}
+	}
+}
+
+func TestReader_CommaSeparatedValues(t *testing.T) {
+	data := []struct {
+		Input    string
+		Trailing bool
+		Want     int
+		Err      bool
+	}{
+		{Input: `{"a":1},{"b":2}`, Want: 2},
+		{Input: "{\"a\":1} ,\n{\"b\":2}\n[3] 4, \"five\"", Want: 5},
+		{Input: `1,2,3`, Want: 3},
+		{Input: `{"a":1},`, Want: 1, Err: true},
+		{Input: `{"a":1},`, Trailing: true, Want: 1},
+		{Input: `{"a":1},,{"b":2}`, Want: 1, Err: true},
+		{Input: `,{"a":1}`, Err: true},
+	}
+	for _, d := range data {
+		r := NewWithOptions(strings.NewReader(d.Input), WithCommaSeparatedValues())
+		r.SetTrailingCommas(d.Trailing)
+		var (
+			count int
+			err   error
+		)
+		for {
+			if _, err = r.Read(); err != nil {
+				break
+			}
+			count++
+		}
+		if count != d.Want {
+			t.Errorf("%s: want %d values, got %d", d.Input, d.Want, count)
+		}
+		if d.Err == errors.Is(err, io.EOF) {
+			t.Errorf("%s: unexpected error %v", d.Input, err)
+		}
+	}
+
+	r := New(strings.NewReader(`{"a":1},{"b":2}`))
+	if _, err := r.Read(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if _, err := r.Read(); err == nil {
+		t.Errorf("comma separated values accepted without option")
+	}
+
+	var count int
+	r = NewWithOptions(strings.NewReader(`[1], [2], [3]`), WithCommaSeparatedValues())
+	for res := range r.Stream(context.Background()) {
+		if res.Err != nil {
+			t.Fatalf("unexpected error: %s", res.Err)
+		}
+		count++
+	}
+	if count != 3 {
+		t.Errorf("want 3 values streamed, got %d", count)
+	}
+}
+
+func TestReader_EmptyAsNull(t *testing.T) {
+	for _, d := range []string{"", "  \n\t"} {
+		r := New(strings.NewReader(d))
+		r.SetEmptyAsNull(true)
 		e, err := r.Read()
-		if err != nil && !errors.Is(err, io.EOF) {
+		if err != nil {
+			t.Errorf("%q: unexpected error: %s", d, err)
+			continue
+		}
+		if !IsNull(e) {
+			t.Errorf("%q: expected null, got %v", d, e)
+		}
+		if _, err := r.Read(); !errors.Is(err, io.EOF) {
+			t.Errorf("%q: expected io.EOF after null, got %v", d, err)
+		}
+	}
+	r := New(strings.NewReader(` "foobar" `))
+	r.SetEmptyAsNull(true)
+	if e, err := r.Read(); err != nil || e.Type() != TypeString {
+		t.Fatalf("unexpected value: %v (%v)", e, err)
+	}
+	if _, err := r.Read(); !errors.Is(err, io.EOF) {
+		t.Errorf("expected io.EOF after last value, got %v", err)
+	}
+}
+
+func TestReader_DuplicateKey(t *testing.T) {
+	const input = `{"name": "foo", "name": "bar"}`
+
+	e, err := New(strings.NewReader(input)).Read()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if v := e.(Object)["name"]; v != String("bar") {
+		t.Errorf("last value should win by default, got %v", v)
+	}
+
+	r := New(strings.NewReader(input))
+	r.SetDuplicateKeyFunc(func(_ string, old, _ Element) (Element, error) {
+		return old, nil
+	})
+	if e, err = r.Read(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if v := e.(Object)["name"]; v != String("foo") {
+		t.Errorf("first value should win, got %v", v)
+	}
+
+	r = New(strings.NewReader(input))
+	r.SetDuplicateKeyFunc(func(key string, _, _ Element) (Element, error) {
+		return nil, fmt.Errorf("%s: duplicate key", key)
+	})
+	if _, err = r.Read(); err == nil {
+		t.Errorf("duplicate key should have been rejected")
+	}
+}
+
+func TestReader_MultiObjects(t *testing.T) {
+	const input = `{"a": 1, "b": {"c": true, "c": false}, "a": 2}`
+
+	e, err := NewWithOptions(strings.NewReader(input), WithMultiObjects()).Read()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	want := MultiObject{
+		"a": {NumberFrom(1), NumberFrom(2)},
+		"b": {MultiObject{"c": {Literal[bool]{Literal: true}, Literal[bool]{Literal: false}}}},
+	}
+	if !Equal(e, want) {
+		t.Fatalf("multi object mismatched: want %v, got %v", want, e)
+	}
+	if v := e.(MultiObject).Object()["a"]; !Equal(v, NumberFrom(2)) {
+		t.Errorf("last value should be kept when flattening, got %v", v)
+	}
+
+	var str strings.Builder
+	if err := NewEncoder(&str).Encode(e); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	const encoded = `{"a":1,"a":2,"b":{"c":true,"c":false}}`
+	if str.String() != encoded {
+		t.Errorf("encoded mismatched: want %s, got %s", encoded, str.String())
+	}
+}
+
+func TestReader_Number(t *testing.T) {
+	data := []struct {
+		Input string
+		Want  float64
+	}{
+		{Input: `5`, Want: 5},
+		{Input: `-5`, Want: -5},
+		{Input: `5e+2`, Want: 500},
+		{Input: `5e-2`, Want: 0.05},
+		{Input: `5E2`, Want: 500},
+		{Input: `0e5`, Want: 0},
+		{Input: `0.5e1`, Want: 5},
+		{Input: `-3.14e2`, Want: -314},
+		{Input: `[3.14E-2]`, Want: 0.0314},
+		{Input: `{"n": 1.5e+1}`, Want: 15},
+	}
+	for _, d := range data {
+		e, err := New(strings.NewReader(d.Input)).Read()
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", d.Input, err)
+			continue
+		}
+		for {
+			if arr, ok := AsArray(e); ok {
+				e = arr[0]
+			} else if obj, ok := AsObject(e); ok {
+				e = obj["n"]
+			} else {
+				break
+			}
+		}
+		if n, _ := AsNumber(e); n != d.Want {
+			t.Errorf("%s: want %f, got %f", d.Input, d.Want, n)
+		}
+	}
+}
+
+func TestReader_NumberFlags(t *testing.T) {
+	data := []struct {
+		Input    string
+		Fraction bool
+		Exponent bool
+	}{
+		{Input: `1000`},
+		{Input: `0`},
+		{Input: `2.0`, Fraction: true},
+		{Input: `0.5`, Fraction: true},
+		{Input: `1e3`, Exponent: true},
+		{Input: `-1E+3`, Exponent: true},
+		{Input: `1.5e3`, Fraction: true, Exponent: true},
+	}
+	for _, d := range data {
+		e, err := NewWithOptions(strings.NewReader(d.Input), WithPreserveRaw()).Read()
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", d.Input, err)
+			continue
+		}
+		n, ok := e.(RawLiteral[float64])
+		if !ok {
+			t.Errorf("%s: expected number, got %T", d.Input, e)
+			continue
+		}
+		if n.HadFraction() != d.Fraction {
+			t.Errorf("%s: fraction mismatched", d.Input)
+		}
+		if n.HadExponent() != d.Exponent {
+			t.Errorf("%s: exponent mismatched", d.Input)
+		}
+	}
+	if e, _ := New(strings.NewReader(`2.0`)).Read(); e != NumberFrom(2) {
+		t.Errorf("parsed literal should compare equal to its value, got %#v", e)
+	}
+	if e, _ := New(strings.NewReader(`"x"`)).Read(); e != (Literal[string]{"x"}) {
+		t.Errorf("parsed literal should compare equal to its value, got %#v", e)
+	}
+}
+
+func TestReader_Integers(t *testing.T) {
+	data := []struct {
+		Input string
+		Want  Element
+	}{
+		{Input: `42`, Want: Literal[int64]{Literal: 42}},
+		{Input: `-0`, Want: Literal[int64]{Literal: 0}},
+		{Input: `9007199254740993`, Want: Literal[int64]{Literal: 9007199254740993}},
+		{Input: `-9223372036854775808`, Want: Literal[int64]{Literal: math.MinInt64}},
+		{Input: `9223372036854775808`, Want: Literal[float64]{Literal: 9223372036854775808}},
+		{Input: `2.0`, Want: Literal[float64]{Literal: 2}},
+		{Input: `1e3`, Want: Literal[float64]{Literal: 1000}},
+	}
+	for _, d := range data {
+		e, err := NewWithOptions(strings.NewReader(d.Input), WithIntegers()).Read()
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", d.Input, err)
+			continue
+		}
+		if e != d.Want {
+			t.Errorf("%s: want %#v, got %#v", d.Input, d.Want, e)
+		}
+	}
+
+	e, err := NewWithOptions(strings.NewReader(`{"id": 12, "ratio": 0.5}`), WithIntegers()).Read()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	obj, _ := AsObject(e)
+	if typ := obj["id"].Type(); typ != TypeInteger {
+		t.Errorf("want %s, got %s", TypeInteger, typ)
+	}
+	if n, ok := AsNumber(obj["id"]); !ok || n != 12 {
+		t.Errorf("integer not usable as number: %v", n)
+	}
+	if _, ok := AsInteger(obj["ratio"]); ok {
+		t.Errorf("float accepted as integer")
+	}
+	if !Equal(obj["id"], Literal[float64]{Literal: 12}) {
+		t.Errorf("integer and float with same value should be equal")
+	}
+	var str strings.Builder
+	if err := NewEncoder(&str).Encode(obj["id"]); err != nil || str.String() != "12" {
+		t.Errorf("unexpected encoded integer %s (%v)", str.String(), err)
+	}
+	var v struct {
+		ID    int64   `json:"id"`
+		Ratio float64 `json:"ratio"`
+	}
+	if err := Decode(e, &v); err != nil || v.ID != 12 || v.Ratio != 0.5 {
+		t.Errorf("unexpected decoded value %+v (%v)", v, err)
+	}
+	if err := (Schema{Type: TypeNumber}).Validate(obj["id"]); err != nil {
+		t.Errorf("integer rejected as number: %s", err)
+	}
+}
+
+func TestLiteral_Narrowest(t *testing.T) {
+	data := []struct {
+		Input string
+		Want  any
+	}{
+		{Input: `42`, Want: int64(42)},
+		{Input: `-7`, Want: int64(-7)},
+		{Input: `2.0`, Want: int64(2)},
+		{Input: `1e3`, Want: int64(1000)},
+		{Input: `0.5`, Want: 0.5},
+		{Input: `9007199254740993`, Want: int64(9007199254740993)},
+		{Input: `9223372036854775808`, Want: 9223372036854775808.0},
+		{Input: `1e300`, Want: 1e300},
+		{Input: `"foo"`, Want: "foo"},
+		{Input: `true`, Want: true},
+	}
+	for _, d := range data {
+		e, err := NewWithOptions(strings.NewReader(d.Input), WithPreserveRaw()).Read()
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", d.Input, err)
+			continue
+		}
+		n, ok := e.(interface{ Narrowest() any })
+		if !ok {
+			t.Errorf("%s: unexpected element %T", d.Input, e)
+			continue
+		}
+		if got := n.Narrowest(); got != d.Want {
+			t.Errorf("%s: want %v (%[2]T), got %v (%[3]T)", d.Input, d.Want, got)
+		}
+	}
+}
+
+func TestLiteral_AsInt(t *testing.T) {
+	data := []struct {
+		Input  string
+		Int64  int64
+		BigInt string
+		Err    bool
+	}{
+		{Input: `"42"`, Int64: 42, BigInt: "42"},
+		{Input: `"-9007199254740993"`, Int64: -9007199254740993, BigInt: "-9007199254740993"},
+		{Input: `"123456789012345678901234567890"`, BigInt: "123456789012345678901234567890", Err: true},
+		{Input: `"1.5"`, Err: true},
+		{Input: `""`, Err: true},
+		{Input: `42`, Err: true},
+	}
+	for _, d := range data {
+		e, err := New(strings.NewReader(d.Input)).Read()
+		if err != nil {
 			t.Errorf("%s: unexpected error: %s", d.Input, err)
 			continue
 		}
-		if e == nil {
-			t.Errorf("%s: nil element received (%s)", d.Input, err)
+		lit, ok := e.(interface {
+			AsInt64() (int64, error)
+			AsBigInt() (*big.Int, error)
+		})
+		if !ok {
+			t.Errorf("%s: unexpected element %T", d.Input, e)
+			continue
+		}
+		n, err := lit.AsInt64()
+		if d.Err != (err != nil) {
+			t.Errorf("%s: unexpected AsInt64 result: %d, %v", d.Input, n, err)
+		} else if n != d.Int64 {
+			t.Errorf("%s: want %d, got %d", d.Input, d.Int64, n)
+		}
+		b, err := lit.AsBigInt()
+		if d.BigInt == "" {
+			if err == nil {
+				t.Errorf("%s: expected error but got %s", d.Input, b)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", d.Input, err)
 			continue
 		}
-		if e.Type() != d.Type {
-			t.Errorf("%s: unexpected element type", d.Input)
+		if b.String() != d.BigInt {
+			t.Errorf("%s: want %s, got %s", d.Input, d.BigInt, b)
+		}
+	}
+}
+
+func TestLiteral_Bytes(t *testing.T) {
+	e, err := New(strings.NewReader(`["foo\u00e9", "", 42]`)).Read()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	arr, _ := AsArray(e)
+	str := arr[0].(Literal[string])
+	if got := str.Bytes(); !bytes.Equal(got, []byte("foo\u00e9")) {
+		t.Errorf("want %q, got %q", "foo\u00e9", got)
+	}
+	lit := String("x")
+	got := lit.Bytes()
+	got[0] = 'y'
+	if lit.Literal != "x" {
+		t.Errorf("Bytes should return a copy, literal changed to %q", lit.Literal)
+	}
+	if got := arr[1].(Literal[string]).Bytes(); len(got) != 0 {
+		t.Errorf("empty string: unexpected bytes %q", got)
+	}
+	if got := arr[2].(Literal[float64]).Bytes(); got != nil {
+		t.Errorf("number: unexpected bytes %q", got)
+	}
+}
+
+func TestReader_Bytes(t *testing.T) {
+	src := []byte(`{"name": "foo", "a\u0062": 1}`)
+	rs := NewBytes(src, WithZeroCopyKeys())
+	e, err := rs.Read()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	var name, escaped string
+	for k := range e.(Object) {
+		if k == "name" {
+			name = k
+		} else {
+			escaped = k
+		}
+	}
+	if n := testing.AllocsPerRun(10, func() { rs.Bytes(name) }); n != 0 {
+		t.Errorf("Bytes should not allocate for aliased key, got %f allocations", n)
+	}
+	if got := rs.Bytes(escaped); string(got) != "ab" {
+		t.Errorf("want %q, got %q", "ab", got)
+	} else if got[0] = 'A'; escaped != "ab" {
+		t.Errorf("Bytes should copy escaped key")
+	}
+	got := rs.Bytes(name)
+	if cap(got) != len(got) {
+		t.Errorf("aliased bytes should not allow appending into the source")
+	}
+	copy(got, "NAME")
+	if string(src[2:6]) != "NAME" {
+		t.Errorf("Bytes does not alias source: %s", src)
+	}
+	if got := rs.Bytes("name"); string(got) != "name" {
+		t.Errorf("unexpected bytes for foreign string: %q", got)
+	}
+
+	src = []byte(`["foo", "b\u0061r", 'baz']`)
+	rs = NewBytes(src, WithZeroCopyStrings(), WithSingleQuotes())
+	if e, err = rs.Read(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	var values []string
+	for _, v := range e.(Array) {
+		str, _ := AsString(v)
+		values = append(values, str)
+	}
+	for i, str := range values {
+		n := testing.AllocsPerRun(10, func() { rs.Bytes(str) })
+		if i != 1 && n != 0 {
+			t.Errorf("%s: Bytes should not allocate for aliased string, got %f allocations", str, n)
+		}
+	}
+	copy(src[2:], "FOO")
+	copy(src[21:], "BAZ")
+	if want := []string{"FOO", "bar", "BAZ"}; !reflect.DeepEqual(want, values) {
+		t.Errorf("strings do not alias source: want %q, got %q", want, values)
+	}
+}
+
+func TestLiteral_Text(t *testing.T) {
+	var (
+		_ encoding.TextMarshaler   = Literal[string]{}
+		_ encoding.TextUnmarshaler = &Literal[string]{}
+		_ encoding.TextMarshaler   = Literal[float64]{}
+		_ encoding.TextUnmarshaler = &Literal[float64]{}
+	)
+	data := []struct {
+		Input Element
+		Want  string
+	}{
+		{Input: String("foo bar"), Want: "foo bar"},
+		{Input: Literal[float64]{Literal: 1.5}, Want: "1.5"},
+		{Input: Literal[float64]{Literal: 1e21}, Want: "1e+21"},
+		{Input: Literal[bool]{Literal: true}, Want: "true"},
+		{Input: Null(), Want: "null"},
+	}
+	for _, d := range data {
+		got, err := d.Input.(encoding.TextMarshaler).MarshalText()
+		if err != nil {
+			t.Errorf("%v: unexpected error: %s", d.Input, err)
 			continue
 		}
+		if string(got) != d.Want {
+			t.Errorf("want %s, got %s", d.Want, got)
+		}
+	}
+	if _, err := (Literal[float64]{Literal: math.Inf(1)}).MarshalText(); err == nil {
+		t.Errorf("infinity marshaled without error")
+	}
+
+	var str Literal[string]
+	if err := str.UnmarshalText([]byte("foo")); err != nil || str.Literal != "foo" {
+		t.Errorf("unexpected string: %v (%v)", str, err)
+	}
+	var num Literal[float64]
+	if err := num.UnmarshalText([]byte("-2.5e3")); err != nil || num.Literal != -2500 {
+		t.Errorf("unexpected number: %v (%v)", num, err)
+	}
+	for _, text := range []string{"", "foo", "NaN", "Inf"} {
+		if err := num.UnmarshalText([]byte(text)); err == nil {
+			t.Errorf("%q: invalid number unmarshaled", text)
+		}
+	}
+	m := map[Literal[string]]int{String("a"): 1}
+	if err := json.NewEncoder(io.Discard).Encode(m); err != nil {
+		t.Errorf("literal as map key: unexpected error: %s", err)
+	}
+}
+
+func TestLiteral_Parse(t *testing.T) {
+	e, err := New(strings.NewReader(`{"payload": "{\"a\": [1, \"b\\n\"]}"}`)).Read()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	payload := e.(Object)["payload"].(Literal[string])
+	got, err := payload.Parse()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	want := Object{"a": Array{NumberFrom(1), String("b\n")}}
+	if !Equal(got, want) {
+		t.Errorf("embedded document mismatched: want %v, got %v", want, got)
+	}
+
+	for _, str := range []string{``, `{"a": 1`, `1 2`, `[1, ]`} {
+		if e, err := String(str).Parse(); err == nil {
+			t.Errorf("%q: invalid json parsed properly as %v", str, e)
+		}
+	}
+	if _, err := String(`[1, ]`).Parse(WithTrailingCommas()); err != nil {
+		t.Errorf("options not applied: %s", err)
+	}
+	if _, err := NumberFrom(1).Parse(); err == nil {
+		t.Errorf("parsing a number should fail")
+	}
+}
+
+func TestAs(t *testing.T) {
+	e, err := New(strings.NewReader(`{"name": "foo", "age": 10, "enabled": true, "tags": [], "parent": null}`)).Read()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	obj, ok := AsObject(e)
+	if !ok {
+		t.Fatalf("expected object, got %T", e)
+	}
+	if _, ok := AsArray(obj); ok {
+		t.Errorf("object should not be an array")
+	}
+	if s, ok := AsString(obj["name"]); !ok || s != "foo" {
+		t.Errorf("unexpected string: %v", obj["name"])
+	}
+	if n, ok := AsNumber(obj["age"]); !ok || n != 10 {
+		t.Errorf("unexpected number: %v", obj["age"])
+	}
+	if b, ok := AsBool(obj["enabled"]); !ok || !b {
+		t.Errorf("unexpected bool: %v", obj["enabled"])
+	}
+	if _, ok := AsArray(obj["tags"]); !ok {
+		t.Errorf("unexpected array: %v", obj["tags"])
+	}
+	if !IsNull(obj["parent"]) || IsNull(obj["name"]) || IsNull(nil) {
+		t.Errorf("null not properly detected")
+	}
+	if _, ok := AsString(obj["age"]); ok {
+		t.Errorf("number should not be a string")
+	}
+	if !IsObject(obj) || IsObject(obj["tags"]) || IsObject(nil) {
+		t.Errorf("object not properly detected")
+	}
+	if !IsArray(obj["tags"]) || IsArray(obj) || IsArray(nil) {
+		t.Errorf("array not properly detected")
+	}
+	for _, k := range []string{"name", "age", "enabled", "parent"} {
+		if !IsScalar(obj[k]) {
+			t.Errorf("%s: scalar not properly detected", k)
+		}
+	}
+	if IsScalar(obj) || IsScalar(obj["tags"]) || IsScalar(nil) {
+		t.Errorf("container detected as scalar")
+	}
+
+	multi := MultiObject{"a": {IntFrom(1), IntFrom(2)}}
+	if !IsObject(multi) || IsArray(multi) || IsScalar(multi) {
+		t.Errorf("multi object not properly detected")
+	}
+	obj, ok = AsObject(multi)
+	if !ok || !Equal(obj, Object{"a": Array{IntFrom(1), IntFrom(2)}}) {
+		t.Errorf("unexpected object for multi object: %v", obj)
+	}
+}
+
+func TestNumberFrom(t *testing.T) {
+	obj := Object{
+		"ratio": NumberFrom(0.25),
+		"id":    IntFrom(math.MaxInt64),
+	}
+	if n, ok := AsNumber(obj["ratio"]); !ok || n != 0.25 {
+		t.Errorf("unexpected number: %v", obj["ratio"])
+	}
+	if n, ok := AsInteger(obj["id"]); !ok || n != math.MaxInt64 {
+		t.Errorf("unexpected integer: %v", obj["id"])
+	}
+	var str strings.Builder
+	enc := NewEncoder(&str)
+	if err := enc.Encode(Array{obj["ratio"], obj["id"]}); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if want := `[0.25,9223372036854775807]`; str.String() != want {
+		t.Errorf("want %s, got %s", want, str.String())
+	}
+}
+
+func TestReader_MaxElements(t *testing.T) {
+	r := New(strings.NewReader(`[1, 2] [3, 4] [5, 6, 7]`))
+	r.SetMaxElements(3)
+	for i := 0; i < 2; i++ {
+		if _, err := r.Read(); err != nil {
+			t.Fatalf("%d: unexpected error: %s", i, err)
+		}
+	}
+	if _, err := r.Read(); err == nil {
+		t.Errorf("element count limit should have been exceeded")
+	}
+}
+
+func TestReader_LastCount(t *testing.T) {
+	r := New(strings.NewReader(`{"a": [1, 2], "b": {"c": null}} "foo" []`))
+	for _, want := range []int{6, 1, 1} {
+		if _, err := r.Read(); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if got := r.LastCount(); got != want {
+			t.Errorf("want %d, got %d", want, got)
+		}
+	}
+	r = New(strings.NewReader(`[[1, 2], 3]`))
+	if _, err := r.ReadIterative(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if got := r.LastCount(); got != 5 {
+		t.Errorf("want %d, got %d", 5, got)
+	}
+}
+
+func TestReader_Escape(t *testing.T) {
+	data := []struct {
+		Input string
+		Want  string
+	}{
+		{Input: `"foo\"bar"`, Want: `foo"bar`},
+		{Input: `"foo\\bar\/"`, Want: `foo\bar/`},
+		{Input: `"\b\f\n\r\t"`, Want: "\b\f\n\r\t"},
+		{Input: `"foo¯bar"`, Want: "foo¯bar"},
+		{Input: `"été"`, Want: "été"},
+		{Input: `"😀"`, Want: "\U0001F600"},
+		{Input: `"\uD83Dfoo"`, Want: "�foo"},
+		{Input: `"\uDE00"`, Want: "�"},
+		{Input: `"\uD83D\n"`, Want: "�\n"},
+		{Input: `"\uD83DA"`, Want: "�A"},
+	}
+	for _, d := range data {
+		e, err := New(strings.NewReader(d.Input)).Read()
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", d.Input, err)
+			continue
+		}
+		if s, _ := AsString(e); s != d.Want {
+			t.Errorf("%s: want %q, got %q", d.Input, d.Want, s)
+		}
+	}
+}
+
+func TestReader_EscapeError(t *testing.T) {
+	data := []struct {
+		Input  string
+		Offset string
+	}{
+		{Input: `"not an escape \e"`, Offset: "offset 15"},
+		{Input: `"not a hex char \uMIDL"`, Offset: "offset 18"},
+		{Input: `["ok", "bad \u00G0"]`, Offset: "offset 16"},
+		{Input: `"\uD83D"`, Offset: "offset 1"},
+		{Input: `["ok", "\uD83D\uD83D"]`, Offset: "offset 14"},
+	}
+	for _, d := range data {
+		_, err := New(strings.NewReader(d.Input)).Read()
+		if err == nil {
+			t.Errorf("%s: expected error", d.Input)
+			continue
+		}
+		if !strings.Contains(err.Error(), d.Offset) {
+			t.Errorf("%s: expected error at %s, got %s", d.Input, d.Offset, err)
+		}
+	}
+}
+
+func TestReader_InvalidUTF8(t *testing.T) {
+	const input = "\"foo\xffbar\""
+
+	if _, err := New(strings.NewReader(input)).Read(); err == nil {
+		t.Errorf("invalid UTF-8 should be rejected by default")
+	}
+	r := New(strings.NewReader(input))
+	r.SetInvalidUTF8Policy(PolicyReplace)
+	e, err := r.Read()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if s, _ := AsString(e); s != "foo�bar" {
+		t.Errorf("invalid UTF-8 not replaced: %q", s)
+	}
+	if _, err := New(strings.NewReader(`"�"`)).Read(); err != nil {
+		t.Errorf("well-formed replacement character should be accepted: %s", err)
+	}
+	for _, d := range []string{"\x91{}", "\x9110", "\x91", "[1,\x91]", "[1, \x91 2]", "{\x91\"a\": 1}"} {
+		for name, read := range map[string]func(*Reader) (Element, error){
+			"read":      (*Reader).Read,
+			"iterative": (*Reader).ReadIterative,
+		} {
+			_, err := read(New(strings.NewReader(d)))
+			if err == nil || !strings.Contains(err.Error(), "invalid UTF-8") {
+				t.Errorf("%s %q: want invalid UTF-8 error, got %v", name, d, err)
+			}
+		}
+	}
+}
+
+func TestReader_ControlChars(t *testing.T) {
+	data := []string{
+		"\"foo\x01bar\"",
+		"\"foo\nbar\"",
+		"{\"foo\tbar\": 1}",
+		"{\"foo\x1f\": 1}",
+		"{\"foo\xffbar\": 1}",
+		"[{\"a\": 1}, {\"\xc3\": 2}]",
+	}
+	for _, d := range data {
+		if e, err := New(strings.NewReader(d)).Read(); err == nil {
+			t.Errorf("%q: invalid string parsed properly as %v", d, e)
+		}
+	}
+	e, err := New(strings.NewReader(`{"foo\tbar": "\u0001"}`)).Read()
+	if err != nil {
+		t.Fatalf("escaped control characters should be accepted: %s", err)
+	}
+	if s, _ := AsString(e.(Object)["foo\tbar"]); s != "\x01" {
+		t.Errorf("unexpected value: %q", s)
+	}
+}
+
+func TestReader_Peek(t *testing.T) {
+	var (
+		r     = New(strings.NewReader(`  {"name": "foo"} [1] "str" -1 true null`))
+		types = []ElementType{TypeObject, TypeArray, TypeString, TypeNumber, TypeBool, TypeNull}
+	)
+	for _, want := range types {
+		got, err := r.Peek()
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if got != want {
+			t.Errorf("peek: want %d, got %d", want, got)
+		}
+		e, err := r.Read()
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if e.Type() != want {
+			t.Errorf("read: want %d, got %d", want, e.Type())
+		}
+	}
+	if _, err := r.Peek(); !errors.Is(err, io.EOF) {
+		t.Errorf("expected io.EOF, got %v", err)
+	}
+}
+
+func TestPeekType(t *testing.T) {
+	data := []struct {
+		Input string
+		Want  ElementType
+	}{
+		{Input: `  {"a": [1, 2]}`, Want: TypeObject},
+		{Input: "\n[true]", Want: TypeArray},
+		{Input: `"foo"`, Want: TypeString},
+		{Input: `-1`, Want: TypeNumber},
+		{Input: `null`, Want: TypeNull},
+		{Input: "\xef\xbb\xbf[]", Want: TypeArray},
+	}
+	for _, d := range data {
+		rs := strings.NewReader(d.Input)
+		got, err := PeekType(rs)
+		if err != nil {
+			t.Errorf("%q: unexpected error: %s", d.Input, err)
+			continue
+		}
+		if got != d.Want {
+			t.Errorf("%q: want %s, got %s", d.Input, d.Want, got)
+		}
+		if _, err := New(rs).Read(); err != nil {
+			t.Errorf("%q: document not readable after peek: %s", d.Input, err)
+		}
+	}
+	if _, err := PeekType(strings.NewReader("  ")); !errors.Is(err, io.EOF) {
+		t.Errorf("expected io.EOF, got %v", err)
+	}
+	if _, err := PeekType(strings.NewReader("/* c */ {}")); err == nil {
+		t.Errorf("comment accepted without option")
+	}
+	if got, err := PeekType(strings.NewReader("/* c */ {}"), WithComments()); err != nil || got != TypeObject {
+		t.Errorf("want %s, got %s (%v)", TypeObject, got, err)
+	}
+}
+
+func TestObject_SortedKeys(t *testing.T) {
+	obj := Object{
+		"name":  String("foo"),
+		"Name":  String("bar"),
+		"été":   Null(),
+		"age":   Null(),
+		"zebra": Null(),
+	}
+	var (
+		want = []string{"Name", "age", "name", "zebra", "été"}
+		got  = obj.SortedKeys()
+	)
+	if len(got) != len(want) {
+		t.Fatalf("want %d keys, got %d", len(want), len(got))
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("%d: want %s, got %s", i, want[i], got[i])
+		}
+	}
+}
+
+func TestReader_MaxNumberLength(t *testing.T) {
+	data := []struct {
+		Input string
+		Valid bool
+	}{
+		{Input: `12345678`, Valid: true},
+		{Input: `-1234567`, Valid: true},
+		{Input: `1.234567`, Valid: true},
+		{Input: `1234e+56`, Valid: true},
+		{Input: `123456789`},
+		{Input: `1.2345678`},
+		{Input: `0.2345678`},
+		{Input: `1234e+567`},
+		{Input: strings.Repeat("9", 1<<20)},
+		{Input: "[" + strings.Repeat("9", 1<<20) + "]"},
+	}
+	for _, d := range data {
+		r := New(strings.NewReader(d.Input))
+		r.SetMaxNumberLength(8)
+		_, err := r.Read()
+		if d.Valid && err != nil {
+			t.Errorf("%.16s: unexpected error: %s", d.Input, err)
+		}
+		if !d.Valid && err == nil {
+			t.Errorf("%.16s: number length limit should have been exceeded", d.Input)
+		}
+	}
+}
+
+func TestReader_Reset(t *testing.T) {
+	r := New(strings.NewReader(`"` + strings.Repeat("x", 1<<20) + `"`))
+	r.SetDuplicateKeyFunc(func(key string, _, _ Element) (Element, error) {
+		return nil, fmt.Errorf("%s: duplicate key", key)
+	})
+	if _, err := r.Read(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	r.Reset(strings.NewReader(`{"a": 1, "a": 2}`))
+	if _, err := r.Read(); err == nil {
+		t.Errorf("reset should keep the reader settings")
+	}
+
+	r.Release()
+	if r.buf.Cap() > maxRetainedBuffer {
+		t.Errorf("large buffer retained after release")
+	}
+	if r.duplicate != nil {
+		t.Errorf("duplicate key function retained after release")
+	}
+	r.Reset(strings.NewReader(`{"a": 1, "a": 2}`))
+	if _, err := r.Read(); err != nil {
+		t.Errorf("unexpected error after release: %s", err)
+	}
+}
+
+func TestReader_ReadWith(t *testing.T) {
+	var (
+		scratch = make([]byte, 0, 256)
+		input   = `{"name": "` + strings.Repeat("x", 100) + `", "n": 1.5}`
+	)
+	for i := 0; i < 3; i++ {
+		el, err := New(strings.NewReader(input)).ReadWith(scratch)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		name, _ := Get(el, "/name")
+		if s, _ := AsString(name); s != strings.Repeat("x", 100) {
+			t.Errorf("unexpected name: %s", s)
+		}
+		if got := string(scratch[:cap(scratch)]); !strings.Contains(got, strings.Repeat("x", 100)) {
+			t.Errorf("name not stored in scratch")
+		}
+	}
+	el, err := New(strings.NewReader(`["` + strings.Repeat("y", 512) + `", "z"]`)).ReadWith(scratch[:0:8])
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if arr, _ := AsArray(el); len(arr) != 2 {
+		t.Errorf("unexpected array: %v", arr)
+	}
+}
+
+func TestReader_ReadWithAllocs(t *testing.T) {
+	var (
+		input   = benchmarkDocument()
+		src     = bytes.NewReader(input)
+		rs      = New(src)
+		scratch = make([]byte, 0, len(input))
+	)
+	allocs := func(read func() (Element, error)) float64 {
+		return testing.AllocsPerRun(10, func() {
+			src.Reset(input)
+			rs.Reset(src)
+			if _, err := read(); err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+		})
+	}
+	plain := allocs(rs.Read)
+	with := allocs(func() (Element, error) {
+		return rs.ReadWith(scratch)
+	})
+	if with > plain*3/4 {
+		t.Errorf("ReadWith: %.0f allocations, want at most three quarters of Read (%.0f)", with, plain)
+	}
+}
+
+func BenchmarkReader_Read(b *testing.B) {
+	benchmarkRead(b, func(r *Reader) (Element, error) {
+		return r.Read()
+	})
+}
+
+func BenchmarkReader_ReadWith(b *testing.B) {
+	scratch := make([]byte, 0, 16<<10)
+	benchmarkRead(b, func(r *Reader) (Element, error) {
+		return r.ReadWith(scratch)
+	})
+}
+
+func benchmarkRead(b *testing.B, read func(*Reader) (Element, error)) {
+	var (
+		input = benchmarkDocument()
+		src   = bytes.NewReader(input)
+		rs    = New(src)
+	)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		src.Reset(input)
+		rs.Reset(src)
+		if _, err := read(rs); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func benchmarkDocument() []byte {
+	var str strings.Builder
+	str.WriteString("[")
+	for i := 0; i < 32; i++ {
+		if i > 0 {
+			str.WriteString(",")
+		}
+		fmt.Fprintf(&str, `{"id": %d, "name": "item-%d", "text": "%s", "price": %d.25}`, i, i, strings.Repeat("lorem ipsum ", 20), i)
+	}
+	str.WriteString("]")
+	return []byte(str.String())
+}
+
+func TestReader_Spans(t *testing.T) {
+	const input = ` {"name": "foo", "a/b": [1, true, {"c~d": null}], "n": -314e-2} `
+
+	r := New(strings.NewReader(input))
+	r.SetRecordSpans(true)
+	if _, err := r.Read(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	want := map[string]string{
+		"":             `{"name": "foo", "a/b": [1, true, {"c~d": null}], "n": -314e-2}`,
+		"/name":        `"foo"`,
+		"/a~1b":        `[1, true, {"c~d": null}]`,
+		"/a~1b/0":      `1`,
+		"/a~1b/1":      `true`,
+		"/a~1b/2":      `{"c~d": null}`,
+		"/a~1b/2/c~0d": `null`,
+		"/n":           `-314e-2`,
+	}
+	spans := r.Spans()
+	if len(spans) != len(want) {
+		t.Errorf("want %d spans, got %d", len(want), len(spans))
+	}
+	for ptr, str := range want {
+		s, ok := spans[ptr]
+		if !ok {
+			t.Errorf("%s: span not recorded", ptr)
+			continue
+		}
+		if got := input[s.Start:s.End]; got != str {
+			t.Errorf("%s: want %s, got %s", ptr, str, got)
+		}
+	}
+}
+
+func TestReader_KeyFilter(t *testing.T) {
+	const input = `{"name": "foo", "password": {"hash": "xxx"}, "mail": "foo@bar.org", "nested": {"password": "yyy"}}`
+
+	r := New(strings.NewReader(input))
+	r.SetKeyFilter(func(key string) (string, bool) {
+		if key == "mail" {
+			return "email", true
+		}
+		return key, key != "password"
+	})
+	e, err := r.Read()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	want := map[string]any{
+		"name":   "foo",
+		"email":  "foo@bar.org",
+		"nested": map[string]any{},
+	}
+	if got := ToGo(e); !reflect.DeepEqual(got, want) {
+		t.Errorf("want %v, got %v", want, got)
+	}
+}
+
+func TestReader_KeyFilterSkip(t *testing.T) {
+	const input = `{"name": "foo", "ssn": "123-45-6789", "meta": {"ssn": ["x", {"y": "z"}]}}`
+
+	for _, iterative := range []bool{false, true} {
+		var (
+			sink  strings.Builder
+			paths []string
+		)
+		r := NewWithOptions(strings.NewReader(input), WithSpans(), WithPreserveFormat())
+		r.SetKeyFilter(func(key string) (string, bool) {
+			return key, key != "ssn"
+		})
+		r.SetValueFilter(func(path string, el Element) (Element, error) {
+			paths = append(paths, path)
+			return el, nil
+		})
+		r.SetStringSink(func(string) io.Writer {
+			return &sink
+		})
+		var err error
+		if iterative {
+			_, err = r.ReadIterative()
+		} else {
+			_, err = r.Read()
+		}
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		for _, p := range paths {
+			if strings.Contains(p, "ssn") {
+				t.Errorf("dropped value given to the value filter: %s", p)
+			}
+		}
+		if strings.Contains(sink.String(), "123") || strings.Contains(sink.String(), "z") {
+			t.Errorf("dropped value written to the string sink: %q", sink.String())
+		}
+		for p := range r.Spans() {
+			if strings.Contains(p, "ssn") {
+				t.Errorf("span recorded for dropped value: %s", p)
+			}
+		}
+		if n := r.LastCount(); n != 3 {
+			t.Errorf("want 3 elements counted, got %d", n)
+		}
+	}
+}
+
+func TestReader_KeyNormalizer(t *testing.T) {
+	const input = `{"Name": "foo", "caf\u0065\u0301": 1, "Nested": {"KEY": true}}`
+
+	compose := strings.NewReplacer("e\u0301", "\u00e9")
+	r := NewWithOptions(strings.NewReader(input), WithKeyNormalizer(func(key string) string {
+		return strings.ToLower(compose.Replace(key))
+	}))
+	e, err := r.Read()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	want := map[string]any{
+		"name":      "foo",
+		"caf\u00e9": 1.0,
+		"nested":    map[string]any{"key": true},
+	}
+	if got := ToGo(e); !reflect.DeepEqual(got, want) {
+		t.Errorf("want %v, got %v", want, got)
+	}
+}
+
+func TestReader_ValueFilter(t *testing.T) {
+	const input = `{"name": "foo", "cards": ["4111111111111111", "5500000000000004"], "age": 42, "tags": []}`
+
+	var paths []string
+	r := New(strings.NewReader(input))
+	r.SetValueFilter(func(path string, el Element) (Element, error) {
+		paths = append(paths, path)
+		if s, ok := AsString(el); ok && len(s) == 16 {
+			return String(strings.Repeat("*", 12) + s[12:]), nil
+		}
+		return el, nil
+	})
+	e, err := r.Read()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	want := map[string]any{
+		"name":  "foo",
+		"cards": []any{"************1111", "************0004"},
+		"age":   float64(42),
+		"tags":  []any{},
+	}
+	if got := ToGo(e); !reflect.DeepEqual(got, want) {
+		t.Errorf("want %v, got %v", want, got)
+	}
+	sort.Strings(paths)
+	if got := strings.Join(paths, ","); got != "/age,/cards/0,/cards/1,/name" {
+		t.Errorf("unexpected paths visited: %s", got)
+	}
+
+	r = New(strings.NewReader(input))
+	r.SetValueFilter(func(path string, el Element) (Element, error) {
+		return nil, fmt.Errorf("%s: rejected", path)
+	})
+	if _, err := r.Read(); err == nil {
+		t.Errorf("filter error should be returned")
+	}
+}
+
+func TestReparseSpan(t *testing.T) {
+	const input = `{"users": [{"name": "foo", "age": 10}, {"name": "bar", "age": 20}], "count": 2}`
+
+	r := NewWithOptions(strings.NewReader(input), WithSpans())
+	if _, err := r.Read(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	spans := r.Spans()
+
+	src := strings.NewReader(input)
+	e, err := ReparseSpan(src, spans["/users/1"])
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	want := map[string]any{"name": "bar", "age": float64(20)}
+	if got := ToGo(e); !reflect.DeepEqual(got, want) {
+		t.Errorf("want %v, got %v", want, got)
+	}
+	if _, err := ReparseSpan(src, Span{Start: 1, End: 20}); err == nil {
+		t.Errorf("invalid span should not be parsed")
+	}
+	if _, err := ReparseSpan(src, Span{Start: 10, End: 5}); err == nil {
+		t.Errorf("invalid range should be rejected")
+	}
+}
+
+func TestReader_ReadInto(t *testing.T) {
+	r := New(strings.NewReader(`{"id": 1, "name": "foo"} {"id": 2} [1, 2, 3] [4] "str"`))
+
+	obj := make(Object)
+	obj["stale"] = Null()
+	e, err := r.ReadInto(obj)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if reflect.ValueOf(e).Pointer() != reflect.ValueOf(obj).Pointer() {
+		t.Errorf("object storage not reused")
+	}
+	if _, ok := obj["stale"]; ok || len(obj) != 2 {
+		t.Errorf("object not cleared before reuse: %v", obj)
+	}
+	if e, err = r.ReadInto(obj); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if want := map[string]any{"id": float64(2)}; !reflect.DeepEqual(ToGo(e), want) {
+		t.Errorf("want %v, got %v", want, e)
+	}
+
+	arr := make(Array, 0, 8)
+	if e, err = r.ReadInto(arr); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if got, _ := AsArray(e); len(got) != 3 || &got[:1][0] != &arr[:1][0] {
+		t.Errorf("array storage not reused")
+	}
+	if e, err = r.ReadInto(arr); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if want := []any{float64(4)}; !reflect.DeepEqual(ToGo(e), want) {
+		t.Errorf("want %v, got %v", want, e)
+	}
+	if e, err = r.ReadInto(arr); err != nil || e.Type() != TypeString {
+		t.Errorf("unexpected value read: %v (%v)", e, err)
+	}
+}
+
+func TestReader_Pushback(t *testing.T) {
+	r := New(strings.NewReader("aé"))
+	if c, _ := r.next(); c != 'a' {
+		t.Fatalf("unexpected rune %c", c)
+	}
+	r.reset()
+	r.reset()
+	if c, _ := r.next(); c != 'a' {
+		t.Fatalf("pushed back rune not returned: %c", c)
+	}
+	if c, _ := r.next(); c != 'é' {
+		t.Fatalf("double reset should not push back twice: %c", c)
+	}
+	if r.offset != 3 {
+		t.Errorf("unexpected offset %d", r.offset)
+	}
+	if _, err := r.next(); !errors.Is(err, io.EOF) {
+		t.Fatalf("expected io.EOF, got %v", err)
+	}
+	r.reset()
+	if _, err := r.next(); !errors.Is(err, io.EOF) {
+		t.Errorf("reset after failed read should be a no-op, got %v", err)
+	}
+
+	data := []string{
+		"[1 ,2 , 3\t]",
+		"[0 ,0.5\n,-0 ]",
+		"{\"a\" :1 ,\"b\":[ ] ,\"c\" : { } }",
+		"[true ,false\r\n,null\t]",
+		"[\"\\uD83Dx\" , \"\\uD83D\\n\"]",
+		"[ [ ] , [ [ ] ] ]",
+		"{\"a\":0}",
+		"[0e1 ,1E+1\t, 1.5 ]",
+	}
+	for _, d := range data {
+		for name, rs := range readers(d) {
+			r := New(rs)
+			if _, err := r.Read(); err != nil {
+				t.Errorf("%q(%s): unexpected error: %s", d, name, err)
+				continue
+			}
+			if _, err := r.Read(); !errors.Is(err, io.EOF) {
+				t.Errorf("%q(%s): expected io.EOF, got %v", d, name, err)
+			}
+		}
+	}
+}
+
+func TestReader_StringSink(t *testing.T) {
+	var (
+		content = strings.Repeat("0123456789abcdef", 1<<16)
+		input   = fmt.Sprintf(`{"name": "file.bin", "content": "%s\n", "tags": ["%s"]}`, content, content)
+		sink    bytes.Buffer
+	)
+	r := New(strings.NewReader(input))
+	r.SetStringSink(func(key string) io.Writer {
+		if key == "content" {
+			return &sink
+		}
+		return nil
+	})
+	e, err := r.Read()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if got := sink.String(); got != content+"\n" {
+		t.Errorf("string not streamed to sink (%d bytes written)", len(got))
+	}
+	obj, _ := AsObject(e)
+	if s, _ := AsString(obj["name"]); s != "file.bin" {
+		t.Errorf("unexpected name: %s", s)
+	}
+	if s, _ := AsString(obj["content"]); s != "" {
+		t.Errorf("streamed string should not be buffered")
+	}
+	if arr, _ := AsArray(obj["tags"]); len(arr) != 1 || arr[0] != String(content) {
+		t.Errorf("string not buffered when sink returns nil")
+	}
+}
+
+func TestObject_SetDelete(t *testing.T) {
+	obj := Object{"name": String("foo"), "age": Null()}
+	obj.Set("age", NumberFrom(10))
+	obj.Set("tags", Array{})
+	obj.Delete("name")
+	obj.Delete("missing")
+
+	want := map[string]any{"age": 10.0, "tags": []any{}}
+	if got := ToGo(obj); !reflect.DeepEqual(got, want) {
+		t.Errorf("want %v, got %v", want, got)
+	}
+}
+
+func TestAll(t *testing.T) {
+	var (
+		arr  = Array{String("a"), NumberFrom(1), Null()}
+		obj  = Object{"c": Null(), "a": String("x"), "b": NumberFrom(2)}
+		keys []string
+	)
+	for i, el := range arr.All() {
+		if !Equal(el, arr[i]) {
+			t.Errorf("%d: want %v, got %v", i, arr[i], el)
+		}
+		if i == 1 {
+			break
+		}
+	}
+	for k, el := range obj.All() {
+		if !Equal(el, obj[k]) {
+			t.Errorf("%s: want %v, got %v", k, obj[k], el)
+		}
+		keys = append(keys, k)
+	}
+	if want := []string{"a", "b", "c"}; !reflect.DeepEqual(keys, want) {
+		t.Errorf("want keys %v, got %v", want, keys)
+	}
+}
+
+func TestObject_GetInsensitive(t *testing.T) {
+	e, err := New(strings.NewReader(`{"UserName": "foo", "ID": 1, "id": 2, "Id": 3, "Ém": true}`)).Read()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	obj, _ := AsObject(e)
+	data := []struct {
+		Key  string
+		Want Element
+	}{
+		{Key: "username", Want: String("foo")},
+		{Key: "USERNAME", Want: String("foo")},
+		{Key: "id", Want: NumberFrom(2)},
+		{Key: "iD", Want: NumberFrom(1)},
+		{Key: "ém", Want: Literal[bool]{Literal: true}},
+		{Key: "missing"},
+	}
+	for _, d := range data {
+		got, ok := obj.GetInsensitive(d.Key)
+		if ok != (d.Want != nil) || !Equal(got, d.Want) {
+			t.Errorf("%s: want %v, got %v (%t)", d.Key, d.Want, got, ok)
+		}
+	}
+	if _, ok := obj["username"]; ok {
+		t.Errorf("original keys should be kept")
+	}
+}
+
+func TestObject_Merge(t *testing.T) {
+	var (
+		left  = Object{"name": String("foo"), "age": Null()}
+		right = Object{"age": String("10"), "tags": Array{}}
+		obj   = left.Merge(right)
+	)
+	want := map[string]any{"name": "foo", "age": "10", "tags": []any{}}
+	if got := ToGo(obj); !reflect.DeepEqual(got, want) {
+		t.Errorf("want %v, got %v", want, got)
+	}
+	if len(left) != 2 || !IsNull(left["age"]) || len(right) != 2 {
+		t.Errorf("merge should not modify its operands")
+	}
+}
+
+func TestArray_Concat(t *testing.T) {
+	var (
+		first  = make(Array, 1, 8)
+		second = Array{String("b"), String("c")}
+	)
+	first[0] = String("a")
+	arr := first.Concat(second, nil, Array{Null()})
+	want := []any{"a", "b", "c", nil}
+	if got := ToGo(arr); !reflect.DeepEqual(got, want) {
+		t.Errorf("want %v, got %v", want, got)
+	}
+	arr[0] = String("z")
+	if first[0] != String("a") {
+		t.Errorf("concat should not share storage with its receiver")
+	}
+}
+
+func FuzzReader(f *testing.F) {
+	files, _ := filepath.Glob("data/*.json")
+	for _, file := range files {
+		buf, err := os.ReadFile(file)
+		if err != nil {
+			f.Fatalf("%s: unexpected error: %s", file, err)
+		}
+		f.Add(buf)
+	}
+	for _, str := range []string{`"fooé😀"`, `-0.5e+3`, `[true, false, null]`, `{"a": {}}`, `"\uD83D`, `01`, `1.`} {
+		f.Add([]byte(str))
+	}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		e, err := New(bytes.NewReader(data)).Read()
+		if err != nil {
+			return
+		}
+		var buf bytes.Buffer
+		if err := NewEncoder(&buf).Encode(e); err != nil {
+			t.Fatalf("%q: parsed value not encoded: %s", data, err)
+		}
+		other, err := New(bytes.NewReader(buf.Bytes())).Read()
+		if err != nil {
+			t.Fatalf("%q: encoded value %s not parsed: %s", data, buf.Bytes(), err)
+		}
+		if !Equal(e, other) {
+			t.Fatalf("%q: encoded value %s not equal to original", data, buf.Bytes())
+		}
+	})
+}
+
+func TestReader_ZeroCopyKeys(t *testing.T) {
+	var (
+		src  = []byte(`{"name": "foo", "a\u0062": [1], "cd": {"ef": true}}`)
+		keys []string
+	)
+	filter := func(key string) (string, bool) {
+		keys = append(keys, key)
+		return key, true
+	}
+	e, err := NewBytes(src, WithZeroCopyKeys(), WithKeyFilter(filter)).Read()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	obj, _ := AsObject(e)
+	for _, k := range []string{"name", "ab", "cd"} {
+		if _, ok := obj[k]; !ok {
+			t.Errorf("%s: key not found", k)
+		}
+	}
+	want := []string{"name", "ab", "cd", "ef"}
+	if !reflect.DeepEqual(keys, want) {
+		t.Fatalf("want %v, got %v", want, keys)
+	}
+	copy(src[2:], "NAME")
+	if keys[0] != "NAME" {
+		t.Errorf("key does not alias source: %s", keys[0])
+	}
+	if keys[1] != "ab" {
+		t.Errorf("escaped key should be copied: %s", keys[1])
+	}
+
+	src = []byte(`{"name": "foo"}`)
+	keys = keys[:0]
+	if _, err := NewBytes(src, WithKeyFilter(filter)).Read(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	copy(src[2:], "NAME")
+	if keys[0] != "name" {
+		t.Errorf("key should be copied without zero-copy option: %s", keys[0])
+	}
+}
+
+func TestReader_Progress(t *testing.T) {
+	input := `[` + strings.Repeat(`"abcdefghi",`, 99) + `"abcdefghi"]`
+	for name, rs := range readers(input) {
+		var calls []int64
+		r := NewWithOptions(rs, WithProgressFunc(100, func(n int64) {
+			calls = append(calls, n)
+		}))
+		if _, err := r.Read(); err != nil {
+			t.Fatalf("%s: unexpected error: %s", name, err)
+		}
+		if len(calls) != len(input)/100 {
+			t.Errorf("%s: want %d calls, got %d", name, len(input)/100, len(calls))
+		}
+		for i, n := range calls {
+			if want := int64(i+1) * 100; n != want {
+				t.Errorf("%s: call %d: want %d bytes, got %d", name, i, want, n)
+			}
+		}
+	}
+}
+
+type growingReader struct {
+	bytes.Buffer
+}
+
+func (g *growingReader) Read(b []byte) (int, error) {
+	if g.Len() == 0 {
+		return 0, io.EOF
+	}
+	return g.Buffer.Read(b)
+}
+
+func TestReader_Resumable(t *testing.T) {
+	var (
+		src    growingReader
+		r      = NewWithOptions(&src, WithResumable(), WithSpans())
+		chunks = []string{`  {"name": "fo`, `o", "list": [1, 2`, `]`, `} [tr`, `ue] `}
+		values []Element
+		spans  []Span
+	)
+	for _, c := range chunks {
+		src.WriteString(c)
+		for {
+			e, err := r.Read()
+			if errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, io.EOF) {
+				break
+			}
+			if err != nil {
+				t.Fatalf("%q: unexpected error: %s", c, err)
+			}
+			values = append(values, e)
+			spans = append(spans, r.Spans()[""])
+		}
+	}
+	if len(values) != 2 {
+		t.Fatalf("want 2 values, got %d", len(values))
+	}
+	want, _ := New(strings.NewReader(`{"name": "foo", "list": [1, 2]}`)).Read()
+	if !Equal(want, values[0]) {
+		t.Errorf("want %v, got %v", want, values[0])
+	}
+	if arr, ok := AsArray(values[1]); !ok || len(arr) != 1 {
+		t.Errorf("unexpected second value: %v", values[1])
+	}
+	if want := []Span{{Start: 2, End: 33}, {Start: 34, End: 40}}; !reflect.DeepEqual(want, spans) {
+		t.Errorf("want spans %v, got %v", want, spans)
+	}
+
+	src.Reset()
+	src.WriteString(`{"name": "fo`)
+	r = New(&src)
+	if _, err := r.Read(); !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("truncated value: want %s, got %v", io.ErrUnexpectedEOF, err)
+	}
+	src.WriteString(`o"}`)
+	if e, err := r.Read(); err == nil && e.Type() == TypeObject {
+		t.Errorf("truncated value should not be resumable by default")
+	}
+
+	src.Reset()
+	r = NewWithOptions(&src, WithResumable())
+	values = values[:0]
+	for _, c := range []string{`12`, `34 -5`, `.`, `5e`, `1 nul`, `l tr`, `ue `} {
+		src.WriteString(c)
+		for {
+			e, err := r.Read()
+			if errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, io.EOF) {
+				break
+			}
+			if err != nil {
+				t.Fatalf("%q: unexpected error: %s", c, err)
+			}
+			values = append(values, e)
+		}
+	}
+	want = Array{NumberFrom(1234), NumberFrom(-55), Null(), Literal[bool]{Literal: true}}
+	if !Equal(want, Array(values)) {
+		t.Errorf("values cut at chunk boundaries: want %v, got %v", want, values)
+	}
+
+	src.Reset()
+	src.WriteString(`"` + strings.Repeat("x", 1<<20) + `"`)
+	r = NewWithOptions(&src, WithResumable(), WithPreserveRaw())
+	if _, err := r.Read(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	r.Release()
+	if r.journal.Cap() > maxRetainedBuffer || r.rawbuf.Cap() > maxRetainedBuffer {
+		t.Errorf("large buffers retained after release")
+	}
+
+	comments := [][]string{
+		{`[1, /* note`, ` */ 2]`},
+		{`[1, /`, "/ x\n 2]"},
+		{`[1, /`, `* x *`, `/ 2]`},
+		{`/* lead`, `ing */ [1, 2]`},
+	}
+	for _, chunks := range comments {
+		src.Reset()
+		r = NewWithOptions(&src, WithResumable(), WithComments())
+		values = values[:0]
+		for _, c := range chunks {
+			src.WriteString(c)
+			e, err := r.Read()
+			if errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, io.EOF) {
+				continue
+			}
+			if err != nil {
+				t.Fatalf("%q: unexpected error: %s", chunks, err)
+			}
+			values = append(values, e)
+		}
+		want := Array{NumberFrom(1), NumberFrom(2)}
+		if len(values) != 1 || !Equal(want, values[0]) {
+			t.Errorf("%q: comment cut at chunk boundary: want %v, got %v", chunks, want, values)
+		}
+	}
+}
+
+func TestReader_Buffered(t *testing.T) {
+	data := []struct {
+		Input string
+		Want  string
+	}{
+		{Input: `{"a": 1}HTTP/1.1`, Want: `HTTP/1.1`},
+		{Input: `[1, 2]`, Want: ``},
+		{Input: `42|rest`, Want: `|rest`},
+		{Input: `42€more`, Want: `€more`},
+	}
+	for _, d := range data {
+		r := New(strings.NewReader(d.Input))
+		if _, err := r.Read(); err != nil {
+			t.Errorf("%s: unexpected error: %s", d.Input, err)
+			continue
+		}
+		got, err := r.Buffered()
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", d.Input, err)
+			continue
+		}
+		if string(got) != d.Want {
+			t.Errorf("%s: want %q, got %q", d.Input, d.Want, got)
+		}
+	}
+}
+
+func TestReader_ReadValue(t *testing.T) {
+	data := []struct {
+		Input string
+		Want  Element
+		Rest  string
+		Err   error
+	}{
+		{Input: `{"a": 1}HTTP/1.1`, Want: Object{"a": NumberFrom(1)}, Rest: `HTTP/1.1`},
+		{Input: `"foo"  `, Want: String("foo"), Rest: `  `},
+		{Input: "{\"a\": 1}\r\nHTTP/1.1", Want: Object{"a": NumberFrom(1)}, Rest: "\r\nHTTP/1.1"},
+		{Input: "42\n\n", Want: NumberFrom(42), Rest: "\n\n"},
+		{Input: `42|rest`, Want: NumberFrom(42), Rest: `|rest`},
+		{Input: ``, Err: io.EOF},
+		{Input: `{"a": [1`, Err: io.ErrUnexpectedEOF},
+	}
+	for _, d := range data {
+		el, rest, err := New(strings.NewReader(d.Input)).ReadValue()
+		if d.Err != nil {
+			if !errors.Is(err, d.Err) {
+				t.Errorf("%q: want %v, got %v", d.Input, d.Err, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%q: unexpected error: %s", d.Input, err)
+			continue
+		}
+		if !Equal(el, d.Want) {
+			t.Errorf("%q: want %v, got %v", d.Input, d.Want, el)
+		}
+		if string(rest) != d.Rest {
+			t.Errorf("%q: want rest %q, got %q", d.Input, d.Rest, rest)
+		}
 	}
 }
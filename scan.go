@@ -0,0 +1,257 @@
+package saj
+
+import (
+	"errors"
+	"fmt"
+	"io"
+)
+
+type event int8
+
+const (
+	evObjectStart event = iota
+	evObjectEnd
+	evArrayStart
+	evArrayEnd
+	evKey
+	evString
+	evNumber
+	evBool
+	evNull
+)
+
+// visitor receives the tokens found by scan. Keys and scalars come with
+// their Element, except strings that are only decoded when text is set.
+type visitor struct {
+	fn   func(event, Element) error
+	text bool
+}
+
+func (v *visitor) emit(ev event, el Element) error {
+	if v.fn == nil {
+		return nil
+	}
+	return v.fn(ev, el)
+}
+
+func (r *Reader) emit(v *visitor, ev event, el Element) error {
+	switch ev {
+	case evKey:
+		r.token = r.keySpan
+	case evObjectStart, evObjectEnd, evArrayStart, evArrayEnd:
+		r.token = Span{Start: r.offset - int64(r.size), End: r.offset}
+	default:
+		r.token = Span{Start: r.tokenStart, End: r.offset}
+	}
+	return v.emit(ev, el)
+}
+
+func (r *Reader) Skip() error {
+	r.count = 0
+	return r.scan(&visitor{})
+}
+
+func (r *Reader) Nth(index int) (Element, error) {
+	if index < 0 {
+		return nil, fmt.Errorf("nth: negative index %d", index)
+	}
+	r.count = 0
+	r.skipBlank()
+	c, err := r.next()
+	if err != nil {
+		return nil, err
+	}
+	if !isArray(c) {
+		return nil, fmt.Errorf("nth: array expected, got %c", c)
+	}
+	err = r.enter()
+	defer r.leave()
+	if err != nil {
+		return nil, err
+	}
+	r.skipBlank()
+	for i := 0; ; i++ {
+		c, err := r.next()
+		if err != nil {
+			return nil, err
+		}
+		if c == rsquare && i == 0 {
+			return nil, fmt.Errorf("nth: index %d out of range (empty array)", index)
+		} else if c == rsquare {
+			return nil, fmt.Errorf("nth: index %d out of range (%d elements)", index, i)
+		}
+		if i > 0 {
+			if c != comma {
+				return nil, fmt.Errorf("array: unexpected character %c", c)
+			}
+			r.skipBlank()
+			if c, err := r.next(); err != nil && !errors.Is(err, io.EOF) {
+				return nil, err
+			} else if c == rsquare && r.trailing {
+				return nil, fmt.Errorf("nth: index %d out of range (%d elements)", index, i)
+			} else if c == rsquare || err != nil {
+				return nil, fmt.Errorf("array: unexpected ',' before ']'")
+			}
+		}
+		r.reset()
+		if i == index {
+			return r.read()
+		}
+		if err := r.scan(&visitor{}); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// scan validates the next value and reports its tokens to v without
+// building the Element tree.
+func (r *Reader) scan(v *visitor) error {
+	defer func() {
+		r.buf.Reset()
+		r.skipBlank()
+	}()
+	r.skipBlank()
+	c, err := r.next()
+	if err != nil {
+		return err
+	}
+	if err := r.incr(); err != nil {
+		return err
+	}
+	r.tokenStart = r.offset - int64(r.size)
+	switch {
+	case r.quoted(c):
+		if !v.text {
+			if _, err := r.literalTo(c, io.Discard); err != nil {
+				return err
+			}
+			return r.emit(v, evString, nil)
+		}
+		el, err := r.literalTo(c, nil)
+		if err != nil {
+			return err
+		}
+		return r.emit(v, evString, el)
+	case isObject(c):
+		return r.scanObject(v)
+	case isArray(c):
+		return r.scanArray(v)
+	case isDigit(c) || isMinus(c):
+		r.reset()
+		el, err := r.number()
+		if err != nil {
+			return err
+		}
+		if i, ok := el.(interface{ withRaw(string) Element }); ok && r.raw {
+			el = i.withRaw(r.decoded())
+		}
+		return r.emit(v, evNumber, el)
+	case c == plus:
+		return fmt.Errorf("number: unexpected leading '+'")
+	case isIdent(c):
+		r.reset()
+		el, err := r.identifier()
+		if err != nil {
+			return err
+		}
+		if IsNull(el) {
+			return r.emit(v, evNull, el)
+		}
+		return r.emit(v, evBool, el)
+	default:
+		return r.unexpected(c)
+	}
+}
+
+func (r *Reader) scanObject(v *visitor) error {
+	err := r.enter()
+	defer r.leave()
+	if err != nil {
+		return err
+	}
+	if err := r.emit(v, evObjectStart, nil); err != nil {
+		return err
+	}
+	for n := 1; ; n++ {
+		key, err := r.key()
+		if err != nil {
+			if errors.Is(err, errEmpty) {
+				break
+			}
+			return err
+		}
+		if err := r.keyLimit(n); err != nil {
+			return err
+		}
+		if err := r.emit(v, evKey, String(key)); err != nil {
+			return err
+		}
+		if err := r.scan(v); err != nil {
+			return err
+		}
+		c, err := r.next()
+		if err != nil {
+			return err
+		}
+		if c == rcurly {
+			return r.emit(v, evObjectEnd, nil)
+		} else if c != comma {
+			return fmt.Errorf("object: unexpected character %c", c)
+		}
+		r.skipBlank()
+		if c, err := r.next(); err != nil && !errors.Is(err, io.EOF) {
+			return err
+		} else if c == rcurly && r.trailing {
+			return r.emit(v, evObjectEnd, nil)
+		} else if c == rcurly || err != nil {
+			return fmt.Errorf("object: unexpected ',' before '}'")
+		}
+		r.reset()
+	}
+	if c, _ := r.next(); c != rcurly {
+		return fmt.Errorf("object: expected '}', got %c", c)
+	}
+	return r.emit(v, evObjectEnd, nil)
+}
+
+func (r *Reader) scanArray(v *visitor) error {
+	err := r.enter()
+	defer r.leave()
+	if err != nil {
+		return err
+	}
+	if err := r.emit(v, evArrayStart, nil); err != nil {
+		return err
+	}
+	r.skipBlank()
+	if c, _ := r.next(); c == rsquare {
+		return r.emit(v, evArrayEnd, nil)
+	}
+	r.reset()
+	for n := 1; ; n++ {
+		if err := r.arrayLimit(n); err != nil {
+			return err
+		}
+		if err := r.scan(v); err != nil {
+			return err
+		}
+		c, err := r.next()
+		if err != nil {
+			return err
+		}
+		if c == rsquare {
+			return r.emit(v, evArrayEnd, nil)
+		} else if c != comma {
+			return fmt.Errorf("array: unexpected character %c", c)
+		}
+		r.skipBlank()
+		if c, err := r.next(); err != nil && !errors.Is(err, io.EOF) {
+			return err
+		} else if c == rsquare && r.trailing {
+			return r.emit(v, evArrayEnd, nil)
+		} else if c == rsquare || err != nil {
+			return fmt.Errorf("array: unexpected ',' before ']'")
+		}
+		r.reset()
+	}
+}
@@ -0,0 +1,73 @@
+package saj
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestReader_Skip(t *testing.T) {
+	r := New(strings.NewReader(`{"a": [1, {"b": null}]} "foo" [true, false] 42`))
+	for i := 0; i < 2; i++ {
+		if err := r.Skip(); err != nil {
+			t.Fatalf("%d: unexpected error: %s", i, err)
+		}
+	}
+	e, err := r.Read()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if arr, ok := AsArray(e); !ok || len(arr) != 2 {
+		t.Errorf("unexpected value after skip: %v", e)
+	}
+	if err := r.Skip(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if err := r.Skip(); err == nil {
+		t.Errorf("expected io.EOF after last value")
+	}
+	if err := New(strings.NewReader(`[1, 2,]`)).Skip(); err == nil {
+		t.Errorf("invalid json skipped without error")
+	}
+}
+
+func TestReader_Nth(t *testing.T) {
+	const input = ` [{"id": 0}, [1, [1]], "two", 3, {"id": 4, "list": [4, 4]}] `
+	data := []struct {
+		Index int
+		Want  string
+	}{
+		{Index: 0, Want: `{"id": 0}`},
+		{Index: 1, Want: `[1, [1]]`},
+		{Index: 2, Want: `"two"`},
+		{Index: 3, Want: `3`},
+		{Index: 4, Want: `{"id": 4, "list": [4, 4]}`},
+	}
+	for _, d := range data {
+		for name, rs := range readers(input) {
+			got, err := New(rs).Nth(d.Index)
+			if err != nil {
+				t.Errorf("%d(%s): unexpected error: %s", d.Index, name, err)
+				continue
+			}
+			want, _ := New(strings.NewReader(d.Want)).Read()
+			if !Equal(want, got) {
+				t.Errorf("%d(%s): want %v, got %v", d.Index, name, want, got)
+			}
+		}
+	}
+	for _, i := range []int{-1, 5, 10} {
+		if e, err := New(strings.NewReader(input)).Nth(i); err == nil {
+			t.Errorf("%d: out of range index gives %v", i, e)
+		}
+	}
+	invalid := []string{`[]`, `{"a": 1}`, `[1, 2,]`, `[1 2]`, `[1, {]`}
+	for _, str := range invalid {
+		if e, err := New(strings.NewReader(str)).Nth(2); err == nil {
+			t.Errorf("%s: invalid input gives %v", str, e)
+		}
+	}
+	r := NewWithOptions(strings.NewReader(`[1, 2,]`), WithTrailingCommas())
+	if _, err := r.Nth(2); err == nil || !strings.Contains(err.Error(), "out of range") {
+		t.Errorf("trailing comma: expected out of range error, got %v", err)
+	}
+}
@@ -0,0 +1,102 @@
+package saj
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+type Schema struct {
+	Type       ElementType
+	Required   []string
+	Properties map[string]Schema
+	Items      *Schema
+}
+
+type SchemaError struct {
+	Path   string
+	Reason string
+}
+
+func (e SchemaError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Path, e.Reason)
+}
+
+type SchemaErrors []SchemaError
+
+func (e SchemaErrors) Error() string {
+	var str strings.Builder
+	for i := range e {
+		if i > 0 {
+			str.WriteString("; ")
+		}
+		str.WriteString(e[i].Error())
+	}
+	return str.String()
+}
+
+func (s Schema) Validate(el Element) error {
+	var (
+		errs    SchemaErrors
+		schemas = map[string]Schema{"": s}
+	)
+	Walk(el, func(path string, el Element) error {
+		s, ok := schemas[path]
+		if !ok {
+			return nil
+		}
+		if s.Type != 0 && !s.Type.accepts(el.Type()) {
+			errs = append(errs, SchemaError{
+				Path:   path,
+				Reason: fmt.Sprintf("expected %s, got %s", s.Type, el.Type()),
+			})
+			return nil
+		}
+		switch el := el.(type) {
+		case Object:
+			errs = append(errs, s.missing(path, func(k string) bool {
+				_, ok := el[k]
+				return ok
+			})...)
+			for k, p := range s.Properties {
+				schemas[path+joinPointer([]string{k})] = p
+			}
+		case MultiObject:
+			errs = append(errs, s.missing(path, func(k string) bool {
+				_, ok := el[k]
+				return ok
+			})...)
+			for k, p := range s.Properties {
+				for i := range el[k] {
+					schemas[path+joinPointer([]string{k, strconv.Itoa(i)})] = p
+				}
+			}
+		case Array:
+			if s.Items == nil {
+				break
+			}
+			for i := range el {
+				schemas[path+joinPointer([]string{strconv.Itoa(i)})] = *s.Items
+			}
+		}
+		return nil
+	})
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+func (s Schema) missing(path string, has func(string) bool) []SchemaError {
+	var errs []SchemaError
+	for _, k := range s.Required {
+		if has(k) {
+			continue
+		}
+		errs = append(errs, SchemaError{
+			Path:   path,
+			Reason: fmt.Sprintf("missing required key %q", k),
+		})
+	}
+	return errs
+}
@@ -0,0 +1,107 @@
+package saj
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestSchema(t *testing.T) {
+	schema := Schema{
+		Type:     TypeObject,
+		Required: []string{"name", "tags"},
+		Properties: map[string]Schema{
+			"name": {Type: TypeString},
+			"age":  {Type: TypeNumber},
+			"tags": {
+				Type:  TypeArray,
+				Items: &Schema{Type: TypeString},
+			},
+		},
+	}
+	data := []struct {
+		Input string
+		Paths []string
+	}{
+		{
+			Input: `{"name": "foo", "age": 10, "tags": ["a", "b"], "other": null}`,
+		},
+		{
+			Input: `{"name": "foo", "tags": []}`,
+		},
+		{
+			Input: `[]`,
+			Paths: []string{""},
+		},
+		{
+			Input: `{"age": "10"}`,
+			Paths: []string{"", "", "/age"},
+		},
+		{
+			Input: `{"name": 42, "tags": ["a", 1, null]}`,
+			Paths: []string{"/name", "/tags/1", "/tags/2"},
+		},
+	}
+	for _, d := range data {
+		e, err := New(strings.NewReader(d.Input)).Read()
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %s", d.Input, err)
+		}
+		err = schema.Validate(e)
+		if len(d.Paths) == 0 {
+			if err != nil {
+				t.Errorf("%s: unexpected error: %s", d.Input, err)
+			}
+			continue
+		}
+		var errs SchemaErrors
+		if !errors.As(err, &errs) {
+			t.Errorf("%s: expected schema errors, got %v", d.Input, err)
+			continue
+		}
+		if len(errs) != len(d.Paths) {
+			t.Errorf("%s: want %d errors, got %d (%s)", d.Input, len(d.Paths), len(errs), errs)
+			continue
+		}
+		for i := range errs {
+			if errs[i].Path != d.Paths[i] {
+				t.Errorf("%s: want error at %q, got %q", d.Input, d.Paths[i], errs[i].Path)
+			}
+		}
+	}
+}
+
+func TestSchema_MultiObject(t *testing.T) {
+	schema := Schema{
+		Type:     TypeObject,
+		Required: []string{"name"},
+		Properties: map[string]Schema{
+			"name": {Type: TypeString},
+		},
+	}
+	data := []struct {
+		Input string
+		Paths []string
+	}{
+		{Input: `{"name": "foo", "name": "bar"}`},
+		{Input: `{"name": "foo", "name": 42}`, Paths: []string{"/name/1"}},
+		{Input: `{"other": 1}`, Paths: []string{""}},
+	}
+	for _, d := range data {
+		e, err := NewWithOptions(strings.NewReader(d.Input), WithMultiObjects()).Read()
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %s", d.Input, err)
+		}
+		err = schema.Validate(e)
+		var errs SchemaErrors
+		if errors.As(err, &errs) != (len(d.Paths) > 0) {
+			t.Errorf("%s: unexpected result: %v", d.Input, err)
+			continue
+		}
+		for i := range errs {
+			if i >= len(d.Paths) || errs[i].Path != d.Paths[i] {
+				t.Errorf("%s: unexpected error: %s", d.Input, errs[i])
+			}
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package saj
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+const recordSeparator = 0x1e
+
+type SeqReader struct {
+	rs      *bufio.Reader
+	record  int
+	started bool
+	err     error
+}
+
+func NewSeqReader(r io.Reader) *SeqReader {
+	return &SeqReader{
+		rs: bufio.NewReader(r),
+	}
+}
+
+func (r *SeqReader) Next() (Element, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+	for {
+		str, err := r.rs.ReadString(recordSeparator)
+		if err != nil && !errors.Is(err, io.EOF) {
+			r.err = err
+			return nil, err
+		}
+		last := err != nil
+		str = strings.TrimSuffix(str, string(rune(recordSeparator)))
+		if !r.started {
+			r.started = true
+			if strings.TrimSpace(str) != "" {
+				r.err = fmt.Errorf("seq: record separator expected at start of input")
+				return nil, r.err
+			}
+		} else if strings.TrimSpace(str) != "" {
+			r.record++
+			// RFC 7464: a final record not terminated by LF may have been
+			// truncated and is silently dropped.
+			if last && !strings.HasSuffix(str, "\n") {
+				break
+			}
+			// A record that fails to parse is reported but does not stop
+			// the sequence: the next call goes on with the following one.
+			return r.parse(str)
+		}
+		if last {
+			break
+		}
+	}
+	r.err = io.EOF
+	return nil, r.err
+}
+
+func (r *SeqReader) parse(record string) (Element, error) {
+	rs := New(strings.NewReader(record))
+	el, err := rs.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("record %d: incomplete value", r.record)
+		}
+		return nil, fmt.Errorf("record %d: %w", r.record, err)
+	}
+	if c, err := rs.next(); err == nil {
+		return nil, fmt.Errorf("record %d: unexpected character %c after value", r.record, c)
+	}
+	return el, nil
+}
@@ -0,0 +1,85 @@
+package saj
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestSeqReader(t *testing.T) {
+	data := []struct {
+		Input string
+		Types []ElementType
+	}{
+		{Input: "", Types: nil},
+		{Input: "\x1e{\"a\": 1}\n\x1e[1, 2]\n\x1e\"foo\"\n", Types: []ElementType{TypeObject, TypeArray, TypeString}},
+		{Input: "\x1e\n\x1etrue\n\x1e\x1enull\n", Types: []ElementType{TypeBool, TypeNull}},
+		{Input: "\x1e{\"a\":\n1}\n", Types: []ElementType{TypeObject}},
+		{Input: "\x1e1\n\x1e{\"a\": [1, 2", Types: []ElementType{TypeNumber}},
+		{Input: "\x1e1\n\x1e123", Types: []ElementType{TypeNumber}},
+	}
+	for _, d := range data {
+		rs := NewSeqReader(strings.NewReader(d.Input))
+		for i := 0; ; i++ {
+			e, err := rs.Next()
+			if errors.Is(err, io.EOF) {
+				if i != len(d.Types) {
+					t.Errorf("%q: expected %d values, got %d", d.Input, len(d.Types), i)
+				}
+				break
+			}
+			if err != nil {
+				t.Fatalf("%q: unexpected error: %s", d.Input, err)
+			}
+			if i >= len(d.Types) {
+				t.Fatalf("%q: too many values read", d.Input)
+			}
+			if e.Type() != d.Types[i] {
+				t.Errorf("%q: %d: unexpected element type", d.Input, i)
+			}
+		}
+	}
+}
+
+func TestSeqReader_Continue(t *testing.T) {
+	data := []struct {
+		Input string
+		Valid []bool
+	}{
+		{Input: "\x1e[1, 2\n\x1e3\n", Valid: []bool{false, true}},
+		{Input: "\x1e{bad}\n", Valid: []bool{false}},
+		{Input: "\x1e1\n\x1e{bad}\n\x1e1 2\n\x1etrue\n\x1e[", Valid: []bool{true, false, false, true}},
+	}
+	for _, d := range data {
+		rs := NewSeqReader(strings.NewReader(d.Input))
+		for i, valid := range d.Valid {
+			e, err := rs.Next()
+			if errors.Is(err, io.EOF) {
+				t.Fatalf("%q: %d: unexpected end of sequence", d.Input, i)
+			}
+			if valid && err != nil {
+				t.Errorf("%q: %d: unexpected error: %s", d.Input, i, err)
+			} else if !valid && err == nil {
+				t.Errorf("%q: %d: invalid record parsed properly as %v", d.Input, i, e)
+			}
+		}
+		if _, err := rs.Next(); !errors.Is(err, io.EOF) {
+			t.Errorf("%q: expected io.EOF, got %v", d.Input, err)
+		}
+	}
+}
+
+func TestSeqReader_Error(t *testing.T) {
+	data := []string{
+		"{\"a\": 1}\n",
+		"\x1e[1, 2\n\x1e3\n",
+		"\x1e1 2\n\x1e3\n",
+	}
+	for _, d := range data {
+		rs := NewSeqReader(strings.NewReader(d))
+		if e, err := rs.Next(); err == nil || errors.Is(err, io.EOF) {
+			t.Errorf("%q: invalid json-seq parsed properly as %v", d, e)
+		}
+	}
+}
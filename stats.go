@@ -0,0 +1,59 @@
+package saj
+
+import (
+	"fmt"
+	"io"
+)
+
+type DocStats struct {
+	Objects  int
+	Arrays   int
+	Strings  int
+	Numbers  int
+	Bools    int
+	Nulls    int
+	Keys     int
+	MaxDepth int
+}
+
+func Stats(r io.Reader) (DocStats, error) {
+	var (
+		st    DocStats
+		depth int
+		rs    = New(r)
+	)
+	err := rs.scan(&visitor{fn: func(ev event, _ Element) error {
+		switch ev {
+		case evObjectStart, evArrayStart:
+			if ev == evObjectStart {
+				st.Objects++
+			} else {
+				st.Arrays++
+			}
+			depth++
+			if depth > st.MaxDepth {
+				st.MaxDepth = depth
+			}
+		case evObjectEnd, evArrayEnd:
+			depth--
+		case evKey:
+			st.Keys++
+		case evString:
+			st.Strings++
+		case evNumber:
+			st.Numbers++
+		case evBool:
+			st.Bools++
+		case evNull:
+			st.Nulls++
+		}
+		return nil
+	}})
+	if err != nil {
+		return st, err
+	}
+	if c, err := rs.next(); err == nil {
+		return st, fmt.Errorf("stats: unexpected character %c after value", c)
+	}
+	return st, nil
+}
@@ -0,0 +1,62 @@
+package saj
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestStats(t *testing.T) {
+	data := []struct {
+		Input string
+		Want  DocStats
+	}{
+		{Input: `"foo"`, Want: DocStats{Strings: 1}},
+		{Input: `[]`, Want: DocStats{Arrays: 1, MaxDepth: 1}},
+		{Input: `{}`, Want: DocStats{Objects: 1, MaxDepth: 1}},
+		{
+			Input: `{"a": [1, 2.5, "x"], "b": {"c": null, "d": [true, false, []]}}`,
+			Want: DocStats{
+				Objects:  2,
+				Arrays:   3,
+				Strings:  1,
+				Numbers:  2,
+				Bools:    2,
+				Nulls:    1,
+				Keys:     4,
+				MaxDepth: 4,
+			},
+		},
+	}
+	for _, d := range data {
+		for _, rs := range readers(d.Input) {
+			got, err := Stats(rs)
+			if err != nil {
+				t.Errorf("%s: unexpected error: %s", d.Input, err)
+				continue
+			}
+			if got != d.Want {
+				t.Errorf("%s: want %+v, got %+v", d.Input, d.Want, got)
+			}
+		}
+	}
+}
+
+func TestStatsInvalid(t *testing.T) {
+	data := []string{
+		``,
+		`{`,
+		`[1,]`,
+		`{"a": 1,}`,
+		`{"a" 1}`,
+		`[1 2]`,
+		`"foo`,
+		`+1`,
+		`nul`,
+		`[] []`,
+	}
+	for _, str := range data {
+		if _, err := Stats(strings.NewReader(str)); err == nil {
+			t.Errorf("%s: invalid json scanned without error", str)
+		}
+	}
+}
@@ -0,0 +1,34 @@
+package saj
+
+import (
+	"context"
+	"errors"
+	"io"
+)
+
+type Result struct {
+	Element Element
+	Err     error
+}
+
+func (r *Reader) Stream(ctx context.Context) <-chan Result {
+	ch := make(chan Result)
+	go func() {
+		defer close(ch)
+		for ctx.Err() == nil {
+			if _, err := r.Peek(); errors.Is(err, io.EOF) {
+				return
+			}
+			el, err := r.Read()
+			select {
+			case ch <- Result{Element: el, Err: err}:
+			case <-ctx.Done():
+				return
+			}
+			if err != nil {
+				return
+			}
+		}
+	}()
+	return ch
+}
@@ -0,0 +1,70 @@
+package saj
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestStream(t *testing.T) {
+	data := []struct {
+		Input string
+		Count int
+		Error bool
+	}{
+		{Input: "", Count: 0},
+		{Input: "1 2 3", Count: 3},
+		{Input: "{\"a\": 1}\n[true]\n\"foo\"\n", Count: 3},
+		{Input: "1 [2, 3", Count: 1, Error: true},
+		{Input: "1 } 2", Count: 1, Error: true},
+	}
+	for _, d := range data {
+		var (
+			count int
+			fail  bool
+		)
+		for res := range New(strings.NewReader(d.Input)).Stream(context.Background()) {
+			if res.Err != nil {
+				fail = true
+				continue
+			}
+			count++
+		}
+		if count != d.Count {
+			t.Errorf("%q: want %d values, got %d", d.Input, d.Count, count)
+		}
+		if fail != d.Error {
+			t.Errorf("%q: want error %t, got %t", d.Input, d.Error, fail)
+		}
+	}
+}
+
+func TestStream_Truncated(t *testing.T) {
+	for _, input := range []string{"[1", `{"a": 1`, `1 "foo`} {
+		var last error
+		for res := range New(strings.NewReader(input)).Stream(context.Background()) {
+			last = res.Err
+		}
+		if !errors.Is(last, io.ErrUnexpectedEOF) {
+			t.Errorf("%q: want %s, got %v", input, io.ErrUnexpectedEOF, last)
+		}
+	}
+}
+
+func TestStreamCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	ch := New(strings.NewReader(strings.Repeat("[1, 2, 3] ", 100))).Stream(ctx)
+	if res := <-ch; res.Err != nil {
+		t.Fatalf("unexpected error: %s", res.Err)
+	}
+	cancel()
+	var count int
+	for range ch {
+		count++
+	}
+	if count > 1 {
+		t.Errorf("producer not stopped after cancel: got %d more values", count)
+	}
+}
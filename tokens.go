@@ -0,0 +1,109 @@
+package saj
+
+import (
+	"errors"
+	"io"
+	"iter"
+)
+
+type TokenType int8
+
+const (
+	// TokenInvalid is the type of the token yielded along with an error.
+	TokenInvalid TokenType = iota
+	TokenObjectStart
+	TokenObjectEnd
+	TokenArrayStart
+	TokenArrayEnd
+	TokenKey
+	TokenValue
+)
+
+func (t TokenType) String() string {
+	switch t {
+	case TokenInvalid:
+		return "invalid"
+	case TokenObjectStart:
+		return "object-start"
+	case TokenObjectEnd:
+		return "object-end"
+	case TokenArrayStart:
+		return "array-start"
+	case TokenArrayEnd:
+		return "array-end"
+	case TokenKey:
+		return "key"
+	case TokenValue:
+		return "value"
+	default:
+		return "unknown"
+	}
+}
+
+// Token is one of the tokens read by Tokens. Key is set for TokenKey and
+// Value for TokenValue. Span is only set when the reader records token
+// spans.
+type Token struct {
+	Type  TokenType
+	Key   string
+	Value Element
+	Span  Span
+}
+
+var errStop = errors.New("stop")
+
+// Tokens returns the tokens of all the values left in the input, up to its
+// end. The sequence stops after the first error.
+func (r *Reader) Tokens() iter.Seq2[Token, error] {
+	return func(yield func(Token, error) bool) {
+		v := visitor{
+			text: true,
+			fn: func(ev event, el Element) error {
+				tok := Token{
+					Type: tokenType(ev),
+				}
+				if tok.Type == TokenKey {
+					tok.Key, _ = AsString(el)
+				} else if tok.Type == TokenValue {
+					tok.Value = el
+				}
+				if r.tokenSpans {
+					tok.Span = r.token
+				}
+				if !yield(tok, nil) {
+					return errStop
+				}
+				return nil
+			},
+		}
+		for {
+			if _, err := r.Peek(); errors.Is(err, io.EOF) {
+				return
+			}
+			r.count = 0
+			if err := r.scan(&v); err != nil {
+				if !errors.Is(err, errStop) {
+					yield(Token{Type: TokenInvalid}, err)
+				}
+				return
+			}
+		}
+	}
+}
+
+func tokenType(ev event) TokenType {
+	switch ev {
+	case evObjectStart:
+		return TokenObjectStart
+	case evObjectEnd:
+		return TokenObjectEnd
+	case evArrayStart:
+		return TokenArrayStart
+	case evArrayEnd:
+		return TokenArrayEnd
+	case evKey:
+		return TokenKey
+	default:
+		return TokenValue
+	}
+}
@@ -0,0 +1,70 @@
+package saj
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestReader_Tokens(t *testing.T) {
+	const input = `{"a": [1, "x"], "b": {}} [true, null] "end"`
+
+	var got []string
+	for tok, err := range New(strings.NewReader(input)).Tokens() {
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		switch tok.Type {
+		case TokenKey:
+			got = append(got, "key:"+tok.Key)
+		case TokenValue:
+			got = append(got, fmt.Sprintf("%s:%v", tok.Value.Type(), Value(tok.Value)))
+		default:
+			got = append(got, tok.Type.String())
+		}
+	}
+	want := []string{
+		"object-start", "key:a", "array-start", "number:1", "string:x", "array-end",
+		"key:b", "object-start", "object-end", "object-end",
+		"array-start", "bool:true", "null:<nil>", "array-end",
+		"string:end",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("want %v, got %v", want, got)
+	}
+}
+
+func TestReader_TokensStop(t *testing.T) {
+	r := NewWithOptions(strings.NewReader(`[1, 2, 3] [4]`), WithTokenSpans())
+
+	var spans []Span
+	for tok, err := range r.Tokens() {
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		spans = append(spans, tok.Span)
+		if tok.Type == TokenValue {
+			break
+		}
+	}
+	if want := []Span{{0, 1}, {1, 2}}; !reflect.DeepEqual(spans, want) {
+		t.Errorf("want spans %v, got %v", want, spans)
+	}
+
+	var (
+		count int
+		last  error
+		typ   TokenType
+	)
+	for tok, err := range New(strings.NewReader(`[1, 2,, 3]`)).Tokens() {
+		count++
+		last, typ = err, tok.Type
+	}
+	if last == nil || count != 4 {
+		t.Errorf("sequence should end with an error after 3 tokens, got %d tokens (%v)", count, last)
+	}
+	if typ != TokenInvalid {
+		t.Errorf("error token: want %s, got %s", TokenInvalid, typ)
+	}
+}
@@ -0,0 +1,55 @@
+package saj
+
+import (
+	"strconv"
+)
+
+func Walk(root Element, fn func(path string, el Element) error) error {
+	return walk(root, nil, fn)
+}
+
+func walk(el Element, path []string, fn func(string, Element) error) error {
+	if err := fn(joinPointer(path), el); err != nil {
+		return err
+	}
+	if m, ok := el.(MultiObject); ok {
+		el = m.lists()
+	}
+	switch el := el.(type) {
+	case Object:
+		for _, k := range el.SortedKeys() {
+			if err := walk(el[k], append(path, k), fn); err != nil {
+				return err
+			}
+		}
+	case Array:
+		for i, v := range el {
+			if err := walk(v, append(path, strconv.Itoa(i)), fn); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func Find(root Element, pred func(path string, el Element) bool) []Element {
+	var list []Element
+	Walk(root, func(path string, el Element) error {
+		if pred(path, el) {
+			list = append(list, el)
+		}
+		return nil
+	})
+	return list
+}
+
+func Leaves(root Element) map[string]Element {
+	list := make(map[string]Element)
+	Walk(root, func(path string, el Element) error {
+		if IsScalar(el) {
+			list[path] = el
+		}
+		return nil
+	})
+	return list
+}
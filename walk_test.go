@@ -0,0 +1,126 @@
+package saj
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestWalk(t *testing.T) {
+	const input = `{"name": "foo", "tags": ["a", {"b/c": true}], "age": 10}`
+
+	e, err := New(strings.NewReader(input)).Read()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	var (
+		want = []string{"", "/age", "/name", "/tags", "/tags/0", "/tags/1", "/tags/1/b~1c"}
+		got  []string
+	)
+	err = Walk(e, func(path string, _ Element) error {
+		got = append(got, path)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("want %v, got %v", want, got)
+	}
+
+	errStop := errors.New("stop")
+	err = Walk(e, func(path string, _ Element) error {
+		if path == "/tags" {
+			return errStop
+		}
+		return nil
+	})
+	if !errors.Is(err, errStop) {
+		t.Errorf("expected walk to be interrupted, got %v", err)
+	}
+}
+
+func TestFind(t *testing.T) {
+	const input = `{"items": [{"type": "file", "name": "a"}, {"name": "b"}, {"type": "dir", "children": [{"type": "file"}]}], "type": "root"}`
+
+	e, err := New(strings.NewReader(input)).Read()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	list := Find(e, func(_ string, el Element) bool {
+		obj, ok := AsObject(el)
+		if !ok {
+			return false
+		}
+		_, ok = obj["type"]
+		return ok
+	})
+	if len(list) != 4 {
+		t.Errorf("want 4 objects with type, got %d", len(list))
+	}
+	list = Find(e, func(path string, el Element) bool {
+		s, ok := AsString(el)
+		return ok && s == "file" && strings.HasSuffix(path, "/type")
+	})
+	if len(list) != 2 {
+		t.Errorf("want 2 files, got %d", len(list))
+	}
+	if list = Find(e, func(string, Element) bool { return false }); len(list) != 0 {
+		t.Errorf("no element should be found")
+	}
+}
+
+func TestLeaves(t *testing.T) {
+	const input = `{"a/b": [1, {"c": null}, []], "d": {}, "e~": "x", "f": {"g": true}}`
+
+	e, err := New(strings.NewReader(input)).Read()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	want := map[string]Element{
+		"/a~1b/0":   NumberFrom(1),
+		"/a~1b/1/c": Null(),
+		"/e~0":      String("x"),
+		"/f/g":      Literal[bool]{Literal: true},
+	}
+	got := Leaves(e)
+	if len(got) != len(want) {
+		t.Fatalf("want %d leaves, got %d: %v", len(want), len(got), got)
+	}
+	for ptr, el := range want {
+		if !Equal(got[ptr], el) {
+			t.Errorf("%s: want %v, got %v", ptr, el, got[ptr])
+		}
+		if v, err := Get(e, ptr); err != nil || !Equal(v, el) {
+			t.Errorf("%s: pointer does not resolve to the leaf (%v)", ptr, err)
+		}
+	}
+	if got := Leaves(String("root")); len(got) != 1 || !Equal(got[""], String("root")) {
+		t.Errorf("scalar root should be its own leaf, got %v", got)
+	}
+}
+
+func TestWalk_MultiObject(t *testing.T) {
+	e, err := NewWithOptions(strings.NewReader(`{"a": 1, "b": {"c": true}, "a": 2}`), WithMultiObjects()).Read()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	var (
+		want = []string{"", "/a", "/a/0", "/a/1", "/b", "/b/0", "/b/0/c", "/b/0/c/0"}
+		got  []string
+	)
+	Walk(e, func(path string, _ Element) error {
+		got = append(got, path)
+		return nil
+	})
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("want %v, got %v", want, got)
+	}
+	leaves := Leaves(e)
+	if len(leaves) != 3 || !Equal(leaves["/a/1"], NumberFrom(2)) {
+		t.Errorf("unexpected leaves: %v", leaves)
+	}
+	if found := Find(e, func(_ string, el Element) bool { return IsScalar(el) }); len(found) != 3 {
+		t.Errorf("want 3 scalars, got %v", found)
+	}
+}